@@ -1,6 +1,9 @@
 package config
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 type ClusterConfig struct {
 	Algorithm      string
@@ -8,18 +11,189 @@ type ClusterConfig struct {
 	BatchSize      int
 	Interval       time.Duration
 	MaxIterations  int
+	Tolerance      float64
 	MiniBatchSize  int
 	MiniBatchIters int
+	Init           string
+	Seed           int64
+	Incremental    bool
+	SpawnDistance  float64
+	Metric         string
+	Eps            float64
+	MinPts         int
+	DBSCANSample   int
+	AutoK          bool
+	AutoKMin       int
+	AutoKMax       int
+	AutoKSample    int
+	QualitySample  int
+	// ReclusterInterval schedules a full recluster of the corpus; 0 disables it.
+	ReclusterInterval time.Duration
+	ReclusterSample   int
+	// KeepGenerations is how many retired generations keep their clusters
+	// for rollback; older ones are deleted after each full recluster.
+	KeepGenerations int
+	// Listen wakes the worker on documents_inserted notifications; polling
+	// then falls back to PollInterval.
+	Listen       bool
+	PollInterval time.Duration
+	Debounce     time.Duration
+	// LeaseTTL is how long a claimed batch stays reserved for one worker;
+	// after it expires another instance may pick the documents up.
+	LeaseTTL time.Duration
+	// RecomputeInterval schedules centroid recomputation; 0 disables it.
+	RecomputeInterval time.Duration
+	// DriftHistoryKeep is how many drift points each cluster keeps; older
+	// ones are deleted after each recomputation.
+	DriftHistoryKeep int
+	// LabelInterval schedules cluster labelling from the LabelDocs top-scored
+	// documents of each cluster; 0 disables it.
+	LabelInterval time.Duration
+	LabelDocs     int
+	// DuplicateInterval schedules near-duplicate detection; documents closer
+	// than DuplicateDistance (cosine) among their DuplicateNeighbors nearest
+	// are grouped. 0 disables it.
+	DuplicateInterval  time.Duration
+	DuplicateDistance  float64
+	DuplicateNeighbors int
+	// OutlierInterval schedules outlier scoring; a document is an outlier when
+	// its distance to the centroid exceeds its cluster's mean by OutlierZ
+	// standard deviations. 0 disables it.
+	OutlierInterval time.Duration
+	OutlierZ        float64
+	// PCADims projects embeddings onto that many principal axes before
+	// fitting; PCAVariance instead keeps the fewest axes explaining that
+	// share of the variance, which must lie in (0, 1). Both 0 disable the PCA
+	// stage.
+	PCADims     int
+	PCAVariance float64
+	// MapInterval schedules the 2D cluster map projection, fitted on
+	// MapSample documents; 0 disables it.
+	MapInterval time.Duration
+	MapSample   int
 }
 
 func DefaultClusterConfig() ClusterConfig {
 	return ClusterConfig{
-		Algorithm:      "kmeans",
-		K:              10,
-		BatchSize:      1000,
-		Interval:       5 * time.Second,
-		MaxIterations:  20,
-		MiniBatchSize:  256,
-		MiniBatchIters: 50,
+		Algorithm:          "kmeans",
+		K:                  10,
+		BatchSize:          1000,
+		Interval:           5 * time.Second,
+		Listen:             true,
+		PollInterval:       time.Minute,
+		Debounce:           500 * time.Millisecond,
+		MaxIterations:      20,
+		Tolerance:          1e-4,
+		MiniBatchSize:      256,
+		MiniBatchIters:     50,
+		Init:               "kmeans++",
+		Seed:               0,
+		Incremental:        true,
+		SpawnDistance:      0.5,
+		Metric:             "cosine",
+		Eps:                0.25,
+		MinPts:             5,
+		DBSCANSample:       5000,
+		AutoK:              false,
+		AutoKMin:           2,
+		AutoKMax:           20,
+		AutoKSample:        500,
+		QualitySample:      1000,
+		ReclusterSample:    20000,
+		KeepGenerations:    3,
+		LeaseTTL:           5 * time.Minute,
+		RecomputeInterval:  10 * time.Minute,
+		DriftHistoryKeep:   1000,
+		LabelInterval:      10 * time.Minute,
+		LabelDocs:          200,
+		DuplicateInterval:  time.Hour,
+		DuplicateDistance:  0.05,
+		DuplicateNeighbors: 10,
+		OutlierInterval:    10 * time.Minute,
+		OutlierZ:           3,
+		MapInterval:        time.Hour,
+		MapSample:          20000,
 	}
 }
+
+func GetClusterConfig() ClusterConfig {
+	cfg := DefaultClusterConfig()
+	cfg.Algorithm = getEnv("CLUSTER_ALGORITHM", cfg.Algorithm)
+	cfg.K = getEnvInt("CLUSTER_K", cfg.K)
+	cfg.AutoK = getEnv("CLUSTER_K", "") == "auto"
+	cfg.AutoKMin = getEnvInt("CLUSTER_AUTO_K_MIN", cfg.AutoKMin)
+	cfg.AutoKMax = getEnvInt("CLUSTER_AUTO_K_MAX", cfg.AutoKMax)
+	cfg.AutoKSample = getEnvInt("CLUSTER_AUTO_K_SAMPLE", cfg.AutoKSample)
+	cfg.QualitySample = getEnvInt("CLUSTER_QUALITY_SAMPLE", cfg.QualitySample)
+	cfg.BatchSize = getEnvInt("CLUSTER_BATCH_SIZE", cfg.BatchSize)
+	cfg.Listen = getEnvBool("CLUSTER_LISTEN", cfg.Listen)
+	cfg.PollInterval = time.Duration(getEnvInt("CLUSTER_POLL_INTERVAL_SEC", int(cfg.PollInterval/time.Second))) * time.Second
+	cfg.Debounce = time.Duration(getEnvInt("CLUSTER_DEBOUNCE_MS", int(cfg.Debounce/time.Millisecond))) * time.Millisecond
+	cfg.MaxIterations = getEnvInt("CLUSTER_MAX_ITERATIONS", cfg.MaxIterations)
+	cfg.MiniBatchSize = getEnvInt("CLUSTER_MINIBATCH_SIZE", cfg.MiniBatchSize)
+	cfg.MiniBatchIters = getEnvInt("CLUSTER_MINIBATCH_ITERS", cfg.MiniBatchIters)
+	cfg.Init = getEnv("CLUSTER_INIT", cfg.Init)
+	cfg.Seed = getEnvInt64("CLUSTER_SEED", cfg.Seed)
+	cfg.Incremental = getEnvBool("CLUSTER_INCREMENTAL", cfg.Incremental)
+	cfg.SpawnDistance = getEnvFloat("CLUSTER_SPAWN_DISTANCE", cfg.SpawnDistance)
+	cfg.Metric = getEnv("CLUSTER_METRIC", cfg.Metric)
+	cfg.Eps = getEnvFloat("CLUSTER_DBSCAN_EPS", cfg.Eps)
+	cfg.MinPts = getEnvInt("CLUSTER_DBSCAN_MIN_PTS", cfg.MinPts)
+	cfg.DBSCANSample = getEnvInt("CLUSTER_DBSCAN_SAMPLE", cfg.DBSCANSample)
+	cfg.ReclusterInterval = time.Duration(getEnvInt("CLUSTER_RECLUSTER_INTERVAL_SEC", 0)) * time.Second
+	cfg.ReclusterSample = getEnvInt("CLUSTER_RECLUSTER_SAMPLE", cfg.ReclusterSample)
+	cfg.KeepGenerations = getEnvInt("CLUSTER_KEEP_GENERATIONS", cfg.KeepGenerations)
+	cfg.LeaseTTL = time.Duration(getEnvInt("CLUSTER_LEASE_SEC", int(cfg.LeaseTTL/time.Second))) * time.Second
+	cfg.RecomputeInterval = getEnvSeconds("CLUSTER_RECOMPUTE_INTERVAL_SEC", cfg.RecomputeInterval)
+	cfg.DriftHistoryKeep = getEnvInt("CLUSTER_DRIFT_HISTORY_KEEP", cfg.DriftHistoryKeep)
+	cfg.LabelInterval = getEnvSeconds("CLUSTER_LABEL_INTERVAL_SEC", cfg.LabelInterval)
+	cfg.LabelDocs = getEnvInt("CLUSTER_LABEL_DOCS", cfg.LabelDocs)
+	cfg.DuplicateInterval = getEnvSeconds("CLUSTER_DUPLICATE_INTERVAL_SEC", cfg.DuplicateInterval)
+	cfg.DuplicateDistance = getEnvFloat("CLUSTER_DUPLICATE_DISTANCE", cfg.DuplicateDistance)
+	cfg.DuplicateNeighbors = getEnvInt("CLUSTER_DUPLICATE_NEIGHBORS", cfg.DuplicateNeighbors)
+	cfg.OutlierInterval = getEnvSeconds("CLUSTER_OUTLIER_INTERVAL_SEC", cfg.OutlierInterval)
+	cfg.OutlierZ = getEnvFloat("CLUSTER_OUTLIER_Z", cfg.OutlierZ)
+	cfg.PCADims = getEnvInt("CLUSTER_PCA_DIMS", cfg.PCADims)
+	cfg.PCAVariance = getEnvFloat("CLUSTER_PCA_VARIANCE", cfg.PCAVariance)
+	cfg.MapInterval = getEnvSeconds("CLUSTER_MAP_INTERVAL_SEC", cfg.MapInterval)
+	cfg.MapSample = getEnvInt("CLUSTER_MAP_SAMPLE", cfg.MapSample)
+	return cfg
+}
+
+// getEnvSeconds reads a duration in whole seconds. Unlike getEnvInt it
+// accepts 0, which disables a scheduled job.
+func getEnvSeconds(key string, def time.Duration) time.Duration {
+	v := getEnv(key, "")
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return def
+	}
+	return time.Duration(n) * time.Second
+}
+
+func getEnvInt64(key string, def int64) int64 {
+	v := getEnv(key, "")
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func getEnvFloat(key string, def float64) float64 {
+	v := getEnv(key, "")
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return def
+	}
+	return f
+}
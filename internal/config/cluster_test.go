@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetClusterConfigZeroIntervalDisables(t *testing.T) {
+	t.Setenv("CLUSTER_RECOMPUTE_INTERVAL_SEC", "0")
+	t.Setenv("CLUSTER_DUPLICATE_INTERVAL_SEC", "0")
+	t.Setenv("CLUSTER_OUTLIER_INTERVAL_SEC", "0")
+	t.Setenv("CLUSTER_MAP_INTERVAL_SEC", "0")
+
+	cfg := GetClusterConfig()
+	if cfg.RecomputeInterval != 0 {
+		t.Fatalf("expected recompute disabled, got %s", cfg.RecomputeInterval)
+	}
+	if cfg.DuplicateInterval != 0 || cfg.OutlierInterval != 0 {
+		t.Fatalf("expected duplicate and outlier scans disabled, got %s and %s", cfg.DuplicateInterval, cfg.OutlierInterval)
+	}
+	if cfg.MapInterval != 0 {
+		t.Fatalf("expected map projection disabled, got %s", cfg.MapInterval)
+	}
+}
+
+func TestGetEnvSeconds(t *testing.T) {
+	t.Setenv("TEST_INTERVAL_SEC", "90")
+	if got := getEnvSeconds("TEST_INTERVAL_SEC", time.Minute); got != 90*time.Second {
+		t.Fatalf("expected 90s, got %s", got)
+	}
+	t.Setenv("TEST_INTERVAL_SEC", "-1")
+	if got := getEnvSeconds("TEST_INTERVAL_SEC", time.Minute); got != time.Minute {
+		t.Fatalf("expected default for a negative value, got %s", got)
+	}
+}
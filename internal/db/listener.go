@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"NeoBIT/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const DocumentsInsertedChannel = "documents_inserted"
+
+// Listen holds a dedicated pool connection LISTENing on channel and signals
+// the returned channel on every notification. Signals are coalesced: a
+// receiver that falls behind sees one pending signal, not a backlog. On
+// connection loss it reconnects with backoff and signals once, since
+// notifications sent while disconnected are lost. The channel is closed when
+// ctx is done.
+func Listen(ctx context.Context, pool *pgxpool.Pool, channel string, log logger.Logger) <-chan struct{} {
+	if log == nil {
+		log = logger.Nop()
+	}
+	out := make(chan struct{}, 1)
+	signal := func() {
+		select {
+		case out <- struct{}{}:
+		default:
+		}
+	}
+
+	go func() {
+		defer close(out)
+		backoff := time.Second
+		for ctx.Err() == nil {
+			connected, err := listen(ctx, pool, channel, signal)
+			if ctx.Err() != nil {
+				return
+			}
+			if connected {
+				backoff = time.Second
+			}
+			log.Warn(ctx, "db listener: connection lost", logger.FieldAny("channel", channel), logger.FieldAny("error", err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+			signal()
+		}
+	}()
+	return out
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, channel string, signal func()) (bool, error) {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	// LISTEN is session state, so the connection must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return true, err
+		}
+		signal()
+	}
+}
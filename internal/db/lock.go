@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Advisory lock keys shared by every instance of the application.
+const (
+	// LockImport elects the instance that imports the dataset.
+	LockImport int64 = 0x4e656f4249540001 + iota
+	// LockClusterWrite is held shared while a worker writes a batch and
+	// exclusively while the cluster set is replaced (first fit, generation
+	// swap, rollback).
+	LockClusterWrite
+	// LockRecluster ensures a single full recluster across instances.
+	LockRecluster
+	// LockDuplicates ensures a single near-duplicate scan across instances.
+	LockDuplicates
+	// LockMap ensures a single cluster map projection across instances.
+	LockMap
+)
+
+// Locker takes Postgres session-level advisory locks. A held lock pins a pool
+// connection until released; if that connection dies the server drops the
+// lock, so a crashed holder never blocks the other instances.
+type Locker struct {
+	pool *pgxpool.Pool
+}
+
+func NewLocker(pool *pgxpool.Pool) *Locker {
+	return &Locker{pool: pool}
+}
+
+// Lock blocks until the exclusive lock on key is acquired or ctx is done.
+func (l *Locker) Lock(ctx context.Context, key int64) (func(), error) {
+	release, _, err := l.acquire(ctx, "SELECT true FROM pg_advisory_lock($1)", key, "pg_advisory_unlock")
+	return release, err
+}
+
+// LockShared blocks until a shared lock on key is acquired or ctx is done.
+func (l *Locker) LockShared(ctx context.Context, key int64) (func(), error) {
+	release, _, err := l.acquire(ctx, "SELECT true FROM pg_advisory_lock_shared($1)", key, "pg_advisory_unlock_shared")
+	return release, err
+}
+
+// TryLock takes the exclusive lock on key if it is free.
+func (l *Locker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	return l.acquire(ctx, "SELECT pg_try_advisory_lock($1)", key, "pg_advisory_unlock")
+}
+
+func (l *Locker) acquire(ctx context.Context, query string, key int64, unlock string) (func(), bool, error) {
+	if l == nil || l.pool == nil {
+		return nil, false, fmt.Errorf("locker: pool is nil")
+	}
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("locker: acquire connection: %w", err)
+	}
+	var ok bool
+	if err := conn.QueryRow(ctx, query, key).Scan(&ok); err != nil {
+		// The lock may have been granted just before a cancellation was
+		// observed, so the connection cannot be trusted back in the pool.
+		_ = conn.Conn().Close(context.Background())
+		conn.Release()
+		return nil, false, fmt.Errorf("locker: lock %d: %w", key, err)
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	release := func() {
+		if _, err := conn.Exec(context.Background(), "SELECT "+unlock+"($1)", key); err != nil {
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}
+	return release, true, nil
+}
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS metric TEXT NOT NULL DEFAULT 'l2';
+
+-- +goose Down
+ALTER TABLE clusters DROP COLUMN IF EXISTS metric;
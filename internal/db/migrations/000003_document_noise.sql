@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS noise BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_documents_unclustered ON documents (id) WHERE cluster_id IS NULL AND NOT noise;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_documents_unclustered;
+
+ALTER TABLE documents DROP COLUMN IF EXISTS noise;
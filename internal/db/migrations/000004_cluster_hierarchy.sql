@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES clusters(id) ON DELETE CASCADE;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS level INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_clusters_parent_id ON clusters (parent_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_clusters_parent_id;
+
+ALTER TABLE clusters DROP COLUMN IF EXISTS level;
+ALTER TABLE clusters DROP COLUMN IF EXISTS parent_id;
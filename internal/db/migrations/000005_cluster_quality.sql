@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS cohesion DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS cluster_quality (
+    id BIGSERIAL PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    k INT NOT NULL,
+    docs INT NOT NULL,
+    inertia DOUBLE PRECISION NOT NULL,
+    silhouette DOUBLE PRECISION NOT NULL,
+    davies_bouldin DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_quality_created_at ON cluster_quality (created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cluster_quality_created_at;
+DROP TABLE IF EXISTS cluster_quality;
+
+ALTER TABLE clusters DROP COLUMN IF EXISTS cohesion;
@@ -0,0 +1,47 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS cluster_runs (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    algorithm TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    k INT NOT NULL DEFAULT 0,
+    seed BIGINT NOT NULL DEFAULT 0,
+    params JSONB NOT NULL DEFAULT '{}',
+    diagnostics JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'running',
+    error TEXT,
+    docs_processed INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_runs_started_at ON cluster_runs (started_at);
+
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS run_id BIGINT REFERENCES cluster_runs(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_clusters_run_id ON clusters (run_id);
+
+ALTER TABLE cluster_quality ADD COLUMN IF NOT EXISTS run_id BIGINT REFERENCES cluster_runs(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cluster_quality_run_id ON cluster_quality (run_id);
+
+-- cluster_id IS NULL records a document the run labelled as noise.
+CREATE TABLE IF NOT EXISTS cluster_assignments (
+    run_id BIGINT NOT NULL REFERENCES cluster_runs(id) ON DELETE CASCADE,
+    document_id BIGINT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    cluster_id BIGINT REFERENCES clusters(id) ON DELETE CASCADE,
+    PRIMARY KEY (run_id, document_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_assignments_document_id ON cluster_assignments (document_id, run_id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cluster_assignments_document_id;
+DROP TABLE IF EXISTS cluster_assignments;
+
+DROP INDEX IF EXISTS idx_cluster_quality_run_id;
+ALTER TABLE cluster_quality DROP COLUMN IF EXISTS run_id;
+
+DROP INDEX IF EXISTS idx_clusters_run_id;
+ALTER TABLE clusters DROP COLUMN IF EXISTS run_id;
+
+DROP INDEX IF EXISTS idx_cluster_runs_started_at;
+DROP TABLE IF EXISTS cluster_runs;
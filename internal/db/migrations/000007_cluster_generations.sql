@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS cluster_generations (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT REFERENCES cluster_runs(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'staging',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    activated_at TIMESTAMPTZ,
+    retired_at TIMESTAMPTZ
+);
+
+-- At most one generation is live at any time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cluster_generations_active ON cluster_generations ((true)) WHERE status = 'active';
+
+INSERT INTO cluster_generations (status, activated_at) VALUES ('active', now());
+
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS generation_id BIGINT REFERENCES cluster_generations(id) ON DELETE CASCADE;
+UPDATE clusters SET generation_id = (SELECT id FROM cluster_generations WHERE status = 'active');
+CREATE INDEX IF NOT EXISTS idx_clusters_generation_id ON clusters (generation_id);
+
+ALTER TABLE cluster_runs ADD COLUMN IF NOT EXISTS generation_id BIGINT REFERENCES cluster_generations(id) ON DELETE SET NULL;
+UPDATE cluster_runs SET generation_id = (SELECT id FROM cluster_generations WHERE status = 'active');
+
+-- +goose Down
+ALTER TABLE cluster_runs DROP COLUMN IF EXISTS generation_id;
+
+DROP INDEX IF EXISTS idx_clusters_generation_id;
+ALTER TABLE clusters DROP COLUMN IF EXISTS generation_id;
+
+DROP INDEX IF EXISTS idx_cluster_generations_active;
+DROP TABLE IF EXISTS cluster_generations;
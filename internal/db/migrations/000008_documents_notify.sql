@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_documents_inserted() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('documents_inserted', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Statement level, so a bulk import sends one notification per statement
+-- rather than one per row.
+CREATE TRIGGER documents_inserted_notify
+    AFTER INSERT ON documents
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_documents_inserted();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS documents_inserted_notify ON documents;
+DROP FUNCTION IF EXISTS notify_documents_inserted();
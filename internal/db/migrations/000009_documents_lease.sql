@@ -0,0 +1,8 @@
+-- +goose Up
+-- A worker claims a batch of unclustered documents by setting leased_until;
+-- other instances skip them until the lease expires, so a crashed worker's
+-- batch is picked up again.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS leased_until TIMESTAMPTZ NULL;
+
+-- +goose Down
+ALTER TABLE documents DROP COLUMN IF EXISTS leased_until;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS drift DOUBLE PRECISION NULL;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS recomputed_at TIMESTAMPTZ NULL;
+
+-- One row per centroid recomputation: how far the centroid moved, measured
+-- with the cluster's metric, and how many documents it averaged.
+CREATE TABLE IF NOT EXISTS cluster_centroid_history (
+    id BIGSERIAL PRIMARY KEY,
+    cluster_id BIGINT NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    drift DOUBLE PRECISION NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_centroid_history_cluster_id ON cluster_centroid_history (cluster_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cluster_centroid_history_cluster_id;
+DROP TABLE IF EXISTS cluster_centroid_history;
+
+ALTER TABLE clusters DROP COLUMN IF EXISTS recomputed_at;
+ALTER TABLE clusters DROP COLUMN IF EXISTS drift;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- label/keywords are generated; the *_override columns are set through
+-- PATCH /clusters/{id} and win over the generated values.
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS label TEXT NULL;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS keywords TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS label_override TEXT NULL;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS keywords_override TEXT[] NULL;
+
+-- +goose Down
+ALTER TABLE clusters DROP COLUMN IF EXISTS keywords_override;
+ALTER TABLE clusters DROP COLUMN IF EXISTS label_override;
+ALTER TABLE clusters DROP COLUMN IF EXISTS keywords;
+ALTER TABLE clusters DROP COLUMN IF EXISTS label;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- A merged cluster is kept rather than deleted so that rollback can point
+-- its documents back at it; merged_by_run hides it until the merge run is
+-- rolled back.
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS merged_into BIGINT NULL REFERENCES clusters(id) ON DELETE SET NULL;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS merged_by_run BIGINT NULL REFERENCES cluster_runs(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE clusters DROP COLUMN IF EXISTS merged_by_run;
+ALTER TABLE clusters DROP COLUMN IF EXISTS merged_into;
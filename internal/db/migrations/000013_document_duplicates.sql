@@ -0,0 +1,17 @@
+-- +goose Up
+-- duplicate_set holds the id of the seed document whose near duplicates
+-- form the set; NULL when the document has no near duplicate.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS duplicate_set BIGINT NULL;
+-- outlier_score is the distance to the cluster centroid; is_outlier marks
+-- scores far above the cluster's mean, and noise documents.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS outlier_score DOUBLE PRECISION NULL;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS is_outlier BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_documents_duplicate_set ON documents (duplicate_set) WHERE duplicate_set IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_documents_duplicate_set;
+
+ALTER TABLE documents DROP COLUMN IF EXISTS is_outlier;
+ALTER TABLE documents DROP COLUMN IF EXISTS outlier_score;
+ALTER TABLE documents DROP COLUMN IF EXISTS duplicate_set;
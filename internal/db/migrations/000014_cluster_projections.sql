@@ -0,0 +1,21 @@
+-- +goose Up
+-- PCA projections fitted before clustering. New documents of a generation
+-- are projected with its latest projection during incremental assignment.
+-- components holds dims rows of source_dims values, row-major.
+CREATE TABLE IF NOT EXISTS cluster_projections (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT REFERENCES cluster_runs(id) ON DELETE SET NULL,
+    generation_id BIGINT NOT NULL REFERENCES cluster_generations(id) ON DELETE CASCADE,
+    dims INT NOT NULL,
+    source_dims INT NOT NULL,
+    explained_variance DOUBLE PRECISION NOT NULL,
+    mean REAL[] NOT NULL,
+    components REAL[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_projections_generation_id ON cluster_projections (generation_id, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cluster_projections_generation_id;
+DROP TABLE IF EXISTS cluster_projections;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Coordinates of the document on the 2D cluster map, from a PCA projection of
+-- the corpus; NULL until the map job has placed the document.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS map_x REAL NULL;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS map_y REAL NULL;
+-- map_rank is the document's position within its cluster over the cluster's
+-- size, set by the map job; reading in map_rank order samples every cluster
+-- in proportion to its size.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS map_rank DOUBLE PRECISION NULL;
+
+-- idx_documents_map leads with cluster_id, so it also serves every lookup
+-- idx_documents_cluster_id did.
+CREATE INDEX IF NOT EXISTS idx_documents_map ON documents (cluster_id, id);
+DROP INDEX IF EXISTS idx_documents_cluster_id;
+CREATE INDEX IF NOT EXISTS idx_documents_map_rank ON documents (map_rank, id) WHERE map_rank IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_documents_map_rank;
+CREATE INDEX IF NOT EXISTS idx_documents_cluster_id ON documents (cluster_id);
+DROP INDEX IF EXISTS idx_documents_map;
+
+ALTER TABLE documents DROP COLUMN IF EXISTS map_rank;
+ALTER TABLE documents DROP COLUMN IF EXISTS map_y;
+ALTER TABLE documents DROP COLUMN IF EXISTS map_x;
@@ -0,0 +1,12 @@
+package db
+
+func DistanceOperator(metric string) string {
+	switch metric {
+	case "l2":
+		return "<->"
+	case "inner_product":
+		return "<#>"
+	default:
+		return "<=>"
+	}
+}
@@ -71,7 +71,49 @@ var (
 		},
 	)
 
+	clusterInertia = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cluster_inertia",
+			Help: "Within-cluster sum of squares of the last clustering run.",
+		},
+	)
+
+	clusterSilhouette = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cluster_silhouette",
+			Help: "Mean silhouette coefficient of the last clustering run (sampled).",
+		},
+	)
+
+	clusterDaviesBouldin = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cluster_davies_bouldin",
+			Help: "Davies-Bouldin index of the last clustering run.",
+		},
+	)
+
+	clusterCohesion = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_cohesion",
+			Help: "Mean distance of cluster members to the centroid.",
+		},
+		[]string{"cluster_id"},
+	)
+
+	clusterDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_centroid_drift",
+			Help: "Distance the centroid moved at its last recomputation.",
+		},
+		[]string{"cluster_id"},
+	)
+
 	registerOnce sync.Once
+
+	// clusterSeries remembers the clusters with per-cluster series, so that
+	// those of deleted clusters can be dropped.
+	clusterSeriesMu sync.Mutex
+	clusterSeries   = make(map[int64]struct{})
 )
 
 func NewRegistry() {
@@ -85,6 +127,11 @@ func NewRegistry() {
 			clusterSizeMax,
 			clusterSizeAvg,
 			pctClustered,
+			clusterInertia,
+			clusterSilhouette,
+			clusterDaviesBouldin,
+			clusterCohesion,
+			clusterDrift,
 		)
 	})
 }
@@ -116,6 +163,59 @@ func SetPctClustered(pct float64) {
 	pctClustered.Set(pct)
 }
 
+func SetClusterQuality(inertia, silhouette, daviesBouldin float64) {
+	clusterInertia.Set(inertia)
+	clusterSilhouette.Set(silhouette)
+	clusterDaviesBouldin.Set(daviesBouldin)
+}
+
+func SetClusterCohesion(clusterID int64, cohesion float64) {
+	trackCluster(clusterID)
+	clusterCohesion.WithLabelValues(strconv.FormatInt(clusterID, 10)).Set(cohesion)
+}
+
+// ResetClusterCohesion drops every cohesion series before a run publishes
+// the values of its whole cluster set.
+func ResetClusterCohesion() {
+	clusterCohesion.Reset()
+}
+
+// ResetClusterDrift drops every drift series before a recompute pass
+// publishes the drift of all clusters.
+func ResetClusterDrift() {
+	clusterDrift.Reset()
+}
+
+// RetainClusters drops the per-cluster series of every cluster not in live.
+func RetainClusters(live []int64) {
+	keep := make(map[int64]struct{}, len(live))
+	for _, id := range live {
+		keep[id] = struct{}{}
+	}
+	clusterSeriesMu.Lock()
+	defer clusterSeriesMu.Unlock()
+	for id := range clusterSeries {
+		if _, ok := keep[id]; ok {
+			continue
+		}
+		delete(clusterSeries, id)
+		label := strconv.FormatInt(id, 10)
+		clusterCohesion.DeleteLabelValues(label)
+		clusterDrift.DeleteLabelValues(label)
+	}
+}
+
+func trackCluster(clusterID int64) {
+	clusterSeriesMu.Lock()
+	clusterSeries[clusterID] = struct{}{}
+	clusterSeriesMu.Unlock()
+}
+
+func SetClusterDrift(clusterID int64, drift float64) {
+	trackCluster(clusterID)
+	clusterDrift.WithLabelValues(strconv.FormatInt(clusterID, 10)).Set(drift)
+}
+
 func Handler() http.Handler {
 	return promhttp.Handler()
 }
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRetainClustersDropsDeletedClusters(t *testing.T) {
+	ResetClusterCohesion()
+	ResetClusterDrift()
+	SetClusterCohesion(1, 0.1)
+	SetClusterCohesion(2, 0.2)
+	SetClusterCohesion(3, 0.3)
+	SetClusterDrift(1, 0.01)
+	SetClusterDrift(3, 0.03)
+
+	RetainClusters([]int64{2})
+
+	if n := testutil.CollectAndCount(clusterCohesion); n != 1 {
+		t.Fatalf("expected 1 cohesion series, got %d", n)
+	}
+	if v := testutil.ToFloat64(clusterCohesion.WithLabelValues("2")); v != 0.2 {
+		t.Fatalf("expected cohesion of cluster 2 to be kept, got %f", v)
+	}
+	if n := testutil.CollectAndCount(clusterDrift); n != 0 {
+		t.Fatalf("expected drift series of deleted clusters dropped, got %d", n)
+	}
+}
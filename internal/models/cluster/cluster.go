@@ -3,11 +3,43 @@ package cluster
 import "time"
 
 type Cluster struct {
-	ID        int64     `json:"id"`
-	Algorithm string    `json:"algorithm"`
-	K         int       `json:"k"`
-	Centroid  []float32 `json:"centroid"`
-	Size      int64     `json:"size"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              int64      `json:"id"`
+	Algorithm       string     `json:"algorithm"`
+	Metric          string     `json:"metric"`
+	K               int        `json:"k"`
+	RunID           *int64     `json:"run_id,omitempty"`
+	GenerationID    *int64     `json:"generation_id,omitempty"`
+	ParentID        *int64     `json:"parent_id,omitempty"`
+	Level           int        `json:"level"`
+	MergedInto      *int64     `json:"merged_into,omitempty"`
+	Active          bool       `json:"active"`
+	Label           *string    `json:"label,omitempty"`
+	Keywords        []string   `json:"keywords"`
+	LabelOverridden bool       `json:"label_overridden"`
+	Centroid        []float32  `json:"centroid"`
+	Size            int64      `json:"size"`
+	Cohesion        *float64   `json:"cohesion,omitempty"`
+	Drift           *float64   `json:"drift,omitempty"`
+	RecomputedAt    *time.Time `json:"recomputed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type TreeNode struct {
+	Cluster
+	Children []TreeNode
+}
+
+// Label is a generated cluster description.
+type Label struct {
+	ClusterID int64
+	Label     string
+	Keywords  []string
+}
+
+// LabelPatch edits a cluster's label by hand. A nil field is left unchanged;
+// an empty one clears the override and restores the generated value.
+type LabelPatch struct {
+	Label    *string
+	Keywords *[]string
 }
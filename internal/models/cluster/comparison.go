@@ -0,0 +1,30 @@
+package cluster
+
+// What the ids of a comparison refer to.
+const (
+	CompareByRun        = "run"
+	CompareByGeneration = "generation"
+)
+
+// OverlapCell counts the documents assigned to cluster A by one clustering
+// and to cluster B by the other; a nil id is noise.
+type OverlapCell struct {
+	A    *int64
+	B    *int64
+	Docs int64
+}
+
+// Comparison measures how much two clusterings agree on the documents both
+// of them assigned. ClustersA and ClustersB label the rows and columns of
+// Overlap; a nil id is noise.
+type Comparison struct {
+	By        string
+	A         int64
+	B         int64
+	Docs      int64
+	ARI       float64
+	NMI       float64
+	ClustersA []*int64
+	ClustersB []*int64
+	Overlap   [][]int64
+}
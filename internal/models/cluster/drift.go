@@ -0,0 +1,33 @@
+package cluster
+
+import "time"
+
+// CentroidStat is a cluster's stored centroid next to the mean of the
+// embeddings currently assigned to it.
+type CentroidStat struct {
+	ClusterID int64
+	Centroid  []float32
+	Mean      []float32
+	Size      int64
+}
+
+// CentroidUpdate moves a cluster's centroid and records the drift.
+type CentroidUpdate struct {
+	ClusterID int64
+	Centroid  []float32
+	Drift     float64
+	Size      int64
+}
+
+type DriftPoint struct {
+	Drift     float64   `json:"drift"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClusterDetail is a cluster with its recent centroid drift history, newest
+// first.
+type ClusterDetail struct {
+	Cluster
+	DriftHistory []DriftPoint
+}
@@ -3,11 +3,56 @@ package cluster
 import "time"
 
 type ClusterResponse struct {
-	ID        int64     `json:"id"`
-	Algorithm string    `json:"algorithm"`
-	K         int       `json:"k"`
-	Centroid  []float32 `json:"centroid"`
+	ID              int64      `json:"id"`
+	Algorithm       string     `json:"algorithm"`
+	Metric          string     `json:"metric"`
+	K               int        `json:"k"`
+	RunID           *int64     `json:"run_id,omitempty"`
+	GenerationID    *int64     `json:"generation_id,omitempty"`
+	ParentID        *int64     `json:"parent_id,omitempty"`
+	Level           int        `json:"level"`
+	MergedInto      *int64     `json:"merged_into,omitempty"`
+	Active          bool       `json:"active"`
+	Label           *string    `json:"label,omitempty"`
+	Keywords        []string   `json:"keywords"`
+	LabelOverridden bool       `json:"label_overridden"`
+	Centroid        []float32  `json:"centroid"`
+	Size            int64      `json:"size"`
+	Cohesion        *float64   `json:"cohesion,omitempty"`
+	Drift           *float64   `json:"drift,omitempty"`
+	RecomputedAt    *time.Time `json:"recomputed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type ClusterTreeResponse struct {
+	ID        int64                 `json:"id"`
+	Algorithm string                `json:"algorithm"`
+	Metric    string                `json:"metric"`
+	K         int                   `json:"k"`
+	ParentID  *int64                `json:"parent_id,omitempty"`
+	Level     int                   `json:"level"`
+	Label     *string               `json:"label,omitempty"`
+	Size      int64                 `json:"size"`
+	Children  []ClusterTreeResponse `json:"children"`
+}
+
+type DriftPointResponse struct {
+	Drift     float64   `json:"drift"`
 	Size      int64     `json:"size"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ClusterDetailResponse struct {
+	ClusterResponse
+	DriftHistory []DriftPointResponse `json:"drift_history"`
+}
+
+type PatchClusterRequest struct {
+	Label    *string   `json:"label"`
+	Keywords *[]string `json:"keywords"`
+}
+
+type MergeClusterRequest struct {
+	TargetID int64 `json:"target_id"`
 }
@@ -0,0 +1,26 @@
+package cluster
+
+import "time"
+
+type CreateJobRequest struct {
+	Scope     string `json:"scope"`
+	Algorithm string `json:"algorithm"`
+	K         int    `json:"k"`
+	AutoK     *bool  `json:"auto_k"`
+}
+
+type JobResponse struct {
+	ID            int64      `json:"id"`
+	Scope         string     `json:"scope"`
+	Algorithm     string     `json:"algorithm"`
+	K             int        `json:"k"`
+	AutoK         bool       `json:"auto_k"`
+	Status        string     `json:"status"`
+	RunIDs        []int64    `json:"run_ids"`
+	DocsProcessed int64      `json:"docs_processed"`
+	DocsTotal     int64      `json:"docs_total"`
+	ETASeconds    *float64   `json:"eta_seconds,omitempty"`
+	Error         *string    `json:"error,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RunResponse struct {
+	ID            int64           `json:"id"`
+	Kind          string          `json:"kind"`
+	Algorithm     string          `json:"algorithm"`
+	Metric        string          `json:"metric"`
+	K             int             `json:"k"`
+	Seed          int64           `json:"seed"`
+	GenerationID  *int64          `json:"generation_id,omitempty"`
+	Params        json.RawMessage `json:"params"`
+	Diagnostics   json.RawMessage `json:"diagnostics"`
+	Status        string          `json:"status"`
+	Error         *string         `json:"error,omitempty"`
+	DocsProcessed int             `json:"docs_processed"`
+	Inertia       *float64        `json:"inertia,omitempty"`
+	Silhouette    *float64        `json:"silhouette,omitempty"`
+	DaviesBouldin *float64        `json:"davies_bouldin,omitempty"`
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    *time.Time      `json:"finished_at,omitempty"`
+}
+
+type ComparisonResponse struct {
+	By        string    `json:"by"`
+	A         int64     `json:"a"`
+	B         int64     `json:"b"`
+	Docs      int64     `json:"docs"`
+	ARI       float64   `json:"ari"`
+	NMI       float64   `json:"nmi"`
+	ClustersA []*int64  `json:"clusters_a"`
+	ClustersB []*int64  `json:"clusters_b"`
+	Overlap   [][]int64 `json:"overlap"`
+}
@@ -0,0 +1,8 @@
+package cluster
+
+const (
+	GenerationStatusStaging = "staging"
+	GenerationStatusActive  = "active"
+	GenerationStatusRetired = "retired"
+	GenerationStatusFailed  = "failed"
+)
@@ -0,0 +1,43 @@
+package cluster
+
+import "time"
+
+const (
+	JobScopeFull        = "full"
+	JobScopeIncremental = "incremental"
+	JobScopeCentroids   = "centroids"
+	JobScopeLabels      = "labels"
+	JobScopeDuplicates  = "duplicates"
+	JobScopeOutliers    = "outliers"
+	JobScopeMap         = "map"
+)
+
+const (
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+type Job struct {
+	ID            int64      `json:"id"`
+	Scope         string     `json:"scope"`
+	Algorithm     string     `json:"algorithm"`
+	K             int        `json:"k"`
+	AutoK         bool       `json:"auto_k"`
+	Status        string     `json:"status"`
+	RunIDs        []int64    `json:"run_ids"`
+	DocsProcessed int64      `json:"docs_processed"`
+	DocsTotal     int64      `json:"docs_total"`
+	ETASeconds    *float64   `json:"eta_seconds,omitempty"`
+	Error         *string    `json:"error,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+type JobRequest struct {
+	Scope     string
+	Algorithm string
+	K         int
+	AutoK     *bool
+}
@@ -0,0 +1,18 @@
+package cluster
+
+import "time"
+
+// Projection is a PCA projection fitted by a run. Embeddings are centred on
+// Mean and multiplied by Components, Dims rows of SourceDims values stored
+// row-major.
+type Projection struct {
+	ID                int64
+	RunID             int64
+	GenerationID      *int64
+	Dims              int
+	SourceDims        int
+	ExplainedVariance float64
+	Mean              []float32
+	Components        []float32
+	CreatedAt         time.Time
+}
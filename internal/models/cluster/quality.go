@@ -0,0 +1,16 @@
+package cluster
+
+import "time"
+
+type Quality struct {
+	ID            int64     `json:"id"`
+	RunID         int64     `json:"run_id"`
+	Algorithm     string    `json:"algorithm"`
+	Metric        string    `json:"metric"`
+	K             int       `json:"k"`
+	Docs          int       `json:"docs"`
+	Inertia       float64   `json:"inertia"`
+	Silhouette    float64   `json:"silhouette"`
+	DaviesBouldin float64   `json:"davies_bouldin"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	RunKindBatch       = "batch"
+	RunKindIncremental = "incremental"
+	RunKindFull        = "full"
+	RunKindRollback    = "rollback"
+	RunKindMerge       = "merge"
+	RunKindSplit       = "split"
+)
+
+const (
+	RunStatusRunning    = "running"
+	RunStatusSucceeded  = "succeeded"
+	RunStatusFailed     = "failed"
+	RunStatusRolledBack = "rolled_back"
+)
+
+type Run struct {
+	ID            int64           `json:"id"`
+	Kind          string          `json:"kind"`
+	Algorithm     string          `json:"algorithm"`
+	Metric        string          `json:"metric"`
+	K             int             `json:"k"`
+	Seed          int64           `json:"seed"`
+	GenerationID  *int64          `json:"generation_id,omitempty"`
+	Params        json.RawMessage `json:"params"`
+	Diagnostics   json.RawMessage `json:"diagnostics"`
+	Status        string          `json:"status"`
+	Error         *string         `json:"error,omitempty"`
+	DocsProcessed int             `json:"docs_processed"`
+	Inertia       *float64        `json:"inertia,omitempty"`
+	Silhouette    *float64        `json:"silhouette,omitempty"`
+	DaviesBouldin *float64        `json:"davies_bouldin,omitempty"`
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    *time.Time      `json:"finished_at,omitempty"`
+}
@@ -3,16 +3,44 @@ package document
 import "time"
 
 type Document struct {
-	ID        int64     `json:"id"`
-	HNID      int64     `json:"hn_id"`
-	Title     string    `json:"title"`
-	URL       string    `json:"url"`
-	By        string    `json:"by"`
-	Score     int       `json:"score"`
-	Time      time.Time `json:"time"`
-	Text      string    `json:"text"`
-	Embedding []float32 `json:"embedding"`
-	ClusterID *int64    `json:"cluster_id,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           int64     `json:"id"`
+	HNID         int64     `json:"hn_id"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	By           string    `json:"by"`
+	Score        int       `json:"score"`
+	Time         time.Time `json:"time"`
+	Text         string    `json:"text"`
+	Embedding    []float32 `json:"embedding"`
+	ClusterID    *int64    `json:"cluster_id,omitempty"`
+	Noise        bool      `json:"noise"`
+	IsOutlier    bool      `json:"is_outlier"`
+	OutlierScore *float64  `json:"outlier_score,omitempty"`
+	DuplicateSet *int64    `json:"duplicate_set,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Orders of a cluster's document list.
+const (
+	OrderID         = "id"
+	OrderCentrality = "centrality"
+	OrderScore      = "score"
+	OrderTime       = "time"
+)
+
+// MapPoint is a document placed on the 2D cluster map.
+type MapPoint struct {
+	ID        int64
+	X         float32
+	Y         float32
+	ClusterID *int64
+	Title     string
+}
+
+// Representative is a document ranked by its distance to the cluster
+// centroid.
+type Representative struct {
+	Document
+	Distance float64
 }
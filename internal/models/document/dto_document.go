@@ -18,16 +18,33 @@ type CreateDocumentResponse struct {
 }
 
 type DocumentResponse struct {
-	ID        int64     `json:"id"`
-	HNID      int64     `json:"hn_id"`
-	Title     string    `json:"title"`
-	URL       string    `json:"url"`
-	By        string    `json:"by"`
-	Score     int       `json:"score"`
-	Time      time.Time `json:"time"`
-	Text      string    `json:"text"`
-	Embedding []float32 `json:"embedding"`
-	ClusterID *int64    `json:"cluster_id,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           int64     `json:"id"`
+	HNID         int64     `json:"hn_id"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	By           string    `json:"by"`
+	Score        int       `json:"score"`
+	Time         time.Time `json:"time"`
+	Text         string    `json:"text"`
+	Embedding    []float32 `json:"embedding"`
+	ClusterID    *int64    `json:"cluster_id,omitempty"`
+	Noise        bool      `json:"noise"`
+	IsOutlier    bool      `json:"is_outlier"`
+	OutlierScore *float64  `json:"outlier_score,omitempty"`
+	DuplicateSet *int64    `json:"duplicate_set,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type MapPointResponse struct {
+	ID        int64   `json:"id"`
+	X         float32 `json:"x"`
+	Y         float32 `json:"y"`
+	ClusterID *int64  `json:"cluster_id"`
+	Title     string  `json:"title"`
+}
+
+type RepresentativeResponse struct {
+	DocumentResponse
+	Distance float64 `json:"distance"`
 }
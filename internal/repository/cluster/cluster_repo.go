@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"NeoBIT/internal/db"
 	"NeoBIT/internal/logger"
 	"NeoBIT/internal/models/cluster"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pgvector/pgvector-go"
 )
@@ -23,6 +25,11 @@ func NewClusterRepo(pool *pgxpool.Pool, log logger.Logger) *ClusterRepo {
 	return &ClusterRepo{pool: pool, log: log}
 }
 
+// conn joins the transaction carried by ctx, if any.
+func (r *ClusterRepo) conn(ctx context.Context) db.Querier {
+	return db.Conn(ctx, r.pool)
+}
+
 func (r *ClusterRepo) Create(ctx context.Context, cluster cluster.Cluster) (int64, error) {
 	if r.pool == nil {
 		return 0, fmt.Errorf("cluster repo: pool is nil")
@@ -30,8 +37,18 @@ func (r *ClusterRepo) Create(ctx context.Context, cluster cluster.Cluster) (int6
 
 	query, args, err := sq.
 		Insert("clusters").
-		Columns("algorithm", "k", "centroid").
-		Values(cluster.Algorithm, cluster.K, pgvector.NewVector(cluster.Centroid)).
+		Columns("algorithm", "metric", "k", "run_id", "generation_id", "parent_id", "level", "centroid", "cohesion").
+		Values(
+			cluster.Algorithm,
+			cluster.Metric,
+			cluster.K,
+			cluster.RunID,
+			sq.Expr("COALESCE(?::bigint, "+activeGeneration+")", cluster.GenerationID),
+			cluster.ParentID,
+			cluster.Level,
+			pgvector.NewVector(cluster.Centroid),
+			cluster.Cohesion,
+		).
 		Suffix("RETURNING id").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -40,7 +57,7 @@ func (r *ClusterRepo) Create(ctx context.Context, cluster cluster.Cluster) (int6
 	}
 
 	var id int64
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&id); err != nil {
 		return 0, fmt.Errorf("insert cluster: %w", err)
 	}
 	return id, nil
@@ -52,9 +69,11 @@ func (r *ClusterRepo) List(ctx context.Context, limit, offset int) ([]cluster.Cl
 	}
 
 	query, args, err := sq.
-		Select("c.id", "c.algorithm", "c.k", "c.centroid", "c.created_at", "c.updated_at", "COALESCE(COUNT(d.id), 0)").
+		Select(clusterColumns...).
 		From("clusters c").
 		LeftJoin("documents d ON d.cluster_id = c.id").
+		Where("c.generation_id = " + activeGeneration).
+		Where("c.merged_by_run IS NULL").
 		GroupBy("c.id").
 		OrderBy("c.id").
 		Limit(uint64(limit)).
@@ -65,10 +84,153 @@ func (r *ClusterRepo) List(ctx context.Context, limit, offset int) ([]cluster.Cl
 		return nil, fmt.Errorf("build list clusters: %w", err)
 	}
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list clusters: %w", err)
 	}
+	return scanClusters(rows)
+}
+
+func (r *ClusterRepo) ListChildren(ctx context.Context, parentID int64, limit, offset int) ([]cluster.Cluster, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select(clusterColumns...).
+		From("clusters c").
+		LeftJoin("documents d ON d.cluster_id = c.id").
+		Where(sq.Eq{"c.parent_id": parentID}).
+		Where("c.merged_by_run IS NULL").
+		GroupBy("c.id").
+		OrderBy("c.id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list child clusters: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list child clusters: %w", err)
+	}
+	return scanClusters(rows)
+}
+
+func (r *ClusterRepo) ListNodes(ctx context.Context) ([]cluster.Cluster, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("c.id", "c.algorithm", "c.metric", "c.k", "c.parent_id", "c.level", "COALESCE(c.label_override, c.label)", "c.created_at", "c.updated_at", "COUNT(d.id)").
+		From("clusters c").
+		LeftJoin("documents d ON d.cluster_id = c.id").
+		Where("c.generation_id = " + activeGeneration).
+		Where("c.merged_by_run IS NULL").
+		GroupBy("c.id").
+		OrderBy("c.id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list cluster nodes: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list cluster nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []cluster.Cluster
+	for rows.Next() {
+		var c cluster.Cluster
+		if err := rows.Scan(
+			&c.ID,
+			&c.Algorithm,
+			&c.Metric,
+			&c.K,
+			&c.ParentID,
+			&c.Level,
+			&c.Label,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+			&c.Size,
+		); err != nil {
+			return nil, fmt.Errorf("scan cluster node: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cluster nodes: %w", err)
+	}
+	return out, nil
+}
+
+// LiveIDs returns the ids of the live clusters of the active generation.
+func (r *ClusterRepo) LiveIDs(ctx context.Context) ([]int64, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("id").
+		From("clusters").
+		Where("generation_id = " + activeGeneration).
+		Where("merged_by_run IS NULL").
+		OrderBy("id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list live cluster ids: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list live cluster ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan live cluster id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate live cluster ids: %w", err)
+	}
+	return ids, nil
+}
+
+var clusterColumns = []string{
+	"c.id",
+	"c.algorithm",
+	"c.metric",
+	"c.k",
+	"c.run_id",
+	"c.generation_id",
+	"c.parent_id",
+	"c.level",
+	"c.merged_into",
+	"(c.generation_id = " + activeGeneration + " AND c.merged_by_run IS NULL)",
+	"COALESCE(c.label_override, c.label)",
+	"COALESCE(c.keywords_override, c.keywords)",
+	"(c.label_override IS NOT NULL OR c.keywords_override IS NOT NULL)",
+	"c.centroid",
+	"c.cohesion",
+	"c.drift",
+	"c.recomputed_at",
+	"c.created_at",
+	"c.updated_at",
+	"COALESCE(COUNT(d.id), 0)",
+}
+
+func scanClusters(rows pgx.Rows) ([]cluster.Cluster, error) {
 	defer rows.Close()
 
 	var out []cluster.Cluster
@@ -79,8 +241,21 @@ func (r *ClusterRepo) List(ctx context.Context, limit, offset int) ([]cluster.Cl
 		if err := rows.Scan(
 			&cluster.ID,
 			&cluster.Algorithm,
+			&cluster.Metric,
 			&cluster.K,
+			&cluster.RunID,
+			&cluster.GenerationID,
+			&cluster.ParentID,
+			&cluster.Level,
+			&cluster.MergedInto,
+			&cluster.Active,
+			&cluster.Label,
+			&cluster.Keywords,
+			&cluster.LabelOverridden,
 			&centroid,
+			&cluster.Cohesion,
+			&cluster.Drift,
+			&cluster.RecomputedAt,
 			&cluster.CreatedAt,
 			&cluster.UpdatedAt,
 			&cluster.Size,
@@ -121,9 +296,222 @@ func (r *ClusterRepo) SizeStats(ctx context.Context) (min float64, max float64,
 		return 0, 0, 0, fmt.Errorf("cluster repo: build size stats: %w", err)
 	}
 
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := r.conn(ctx).QueryRow(ctx, query, args...)
 	if err := row.Scan(&min, &max, &avg); err != nil {
 		return 0, 0, 0, fmt.Errorf("cluster repo: size stats: %w", err)
 	}
 	return min, max, avg, nil
 }
+
+func (r *ClusterRepo) Count(ctx context.Context, metric string) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("COUNT(*)").
+		From("clusters").
+		Where(sq.Eq{"metric": metric}).
+		Where("generation_id = " + activeGeneration).
+		Where("merged_by_run IS NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build count clusters: %w", err)
+	}
+
+	var count int64
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count clusters: %w", err)
+	}
+	return count, nil
+}
+
+func (r *ClusterRepo) Nearest(ctx context.Context, embedding []float32, metric string) (cluster.Cluster, float64, error) {
+	if r.pool == nil {
+		return cluster.Cluster{}, 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+	vec := pgvector.NewVector(embedding)
+
+	query, args, err := sq.
+		Select(
+			"c.id",
+			"c.algorithm",
+			"c.metric",
+			"c.k",
+			"c.parent_id",
+			"c.level",
+			"c.centroid",
+			"c.created_at",
+			"c.updated_at",
+			"(SELECT COUNT(*) FROM documents d WHERE d.cluster_id = c.id)",
+		).
+		Column(sq.Expr("c.centroid "+db.DistanceOperator(metric)+" ? AS dist", vec)).
+		From("clusters c").
+		Where(sq.Eq{"c.metric": metric}).
+		Where("c.generation_id = " + activeGeneration).
+		Where("c.merged_by_run IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM clusters ch WHERE ch.parent_id = c.id AND ch.merged_by_run IS NULL)").
+		OrderBy("dist").
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return cluster.Cluster{}, 0, fmt.Errorf("build nearest cluster: %w", err)
+	}
+
+	var c cluster.Cluster
+	var centroid pgvector.Vector
+	var dist float64
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(
+		&c.ID,
+		&c.Algorithm,
+		&c.Metric,
+		&c.K,
+		&c.ParentID,
+		&c.Level,
+		&centroid,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.Size,
+		&dist,
+	); err != nil {
+		return cluster.Cluster{}, 0, fmt.Errorf("nearest cluster: %w", err)
+	}
+	c.Centroid = centroid.Slice()
+	return c, dist, nil
+}
+
+// Leaves returns the clusters Nearest chooses from, with their centroids and
+// sizes, for callers that search them in memory.
+func (r *ClusterRepo) Leaves(ctx context.Context, metric string) ([]cluster.Cluster, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select(
+			"c.id",
+			"c.algorithm",
+			"c.metric",
+			"c.k",
+			"c.parent_id",
+			"c.level",
+			"c.centroid",
+			"c.created_at",
+			"c.updated_at",
+			"(SELECT COUNT(*) FROM documents d WHERE d.cluster_id = c.id)",
+		).
+		From("clusters c").
+		Where(sq.Eq{"c.metric": metric}).
+		Where("c.generation_id = " + activeGeneration).
+		Where("c.merged_by_run IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM clusters ch WHERE ch.parent_id = c.id AND ch.merged_by_run IS NULL)").
+		OrderBy("c.id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list leaf clusters: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list leaf clusters: %w", err)
+	}
+	defer rows.Close()
+
+	var out []cluster.Cluster
+	for rows.Next() {
+		var c cluster.Cluster
+		var centroid pgvector.Vector
+		if err := rows.Scan(
+			&c.ID,
+			&c.Algorithm,
+			&c.Metric,
+			&c.K,
+			&c.ParentID,
+			&c.Level,
+			&centroid,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+			&c.Size,
+		); err != nil {
+			return nil, fmt.Errorf("scan leaf cluster: %w", err)
+		}
+		c.Centroid = centroid.Slice()
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate leaf clusters: %w", err)
+	}
+	return out, nil
+}
+
+func (r *ClusterRepo) UpdateCentroid(ctx context.Context, id int64, centroid []float32) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Update("clusters").
+		Set("centroid", pgvector.NewVector(centroid)).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build update centroid: %w", err)
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("update centroid: %w", err)
+	}
+	return nil
+}
+
+// DeleteEmpty removes live clusters of the active generation that currently
+// hold no documents and have no children. Clusters merged away are not live
+// and are kept, since rollback revives them. Assignments older runs made to a
+// deleted cluster go with it, so a rollback to such a run puts those
+// documents back into their previous surviving cluster, or leaves them for
+// the worker.
+func (r *ClusterRepo) DeleteEmpty(ctx context.Context) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tag, err := r.conn(ctx).Exec(ctx, `
+		DELETE FROM clusters c
+		WHERE c.generation_id = `+activeGeneration+`
+		  AND c.merged_by_run IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM documents d WHERE d.cluster_id = c.id)
+		  AND NOT EXISTS (SELECT 1 FROM clusters ch WHERE ch.parent_id = c.id)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete empty clusters: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *ClusterRepo) SaveQuality(ctx context.Context, q cluster.Quality) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Insert("cluster_quality").
+		Columns("run_id", "algorithm", "metric", "k", "docs", "inertia", "silhouette", "davies_bouldin").
+		Values(q.RunID, q.Algorithm, q.Metric, q.K, q.Docs, q.Inertia, q.Silhouette, q.DaviesBouldin).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build insert cluster quality: %w", err)
+	}
+
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("insert cluster quality: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+	"github.com/jackc/pgx/v5"
+)
+
+// snapshot selects, for every document, the latest assignment of the
+// clustering a run or generation left behind. The clustering after run T is
+// what the finished runs of T's generation up to T assigned, minus the runs a
+// rollback between them and T undid: a run X is skipped when a rollback run
+// with X < id <= T targeted a run before X. Runs rolled back only after T
+// still count, since they were live when T finished. A generation's
+// clustering is the one after its latest finished run.
+func snapshot(by, param string) (string, error) {
+	var target string
+	switch by {
+	case cluster.CompareByRun:
+		target = param
+	case cluster.CompareByGeneration:
+		target = "(SELECT MAX(id) FROM cluster_runs WHERE generation_id = " + param + " AND status IN ($3, $4))"
+	default:
+		return "", fmt.Errorf("unknown comparison %q", by)
+	}
+	return `
+		SELECT DISTINCT ON (x.document_id) x.document_id, x.cluster_id
+		FROM cluster_assignments x
+		JOIN cluster_runs r ON r.id = x.run_id
+		WHERE r.status IN ($3, $4)
+		  AND r.generation_id = (SELECT generation_id FROM cluster_runs WHERE id = ` + target + `)
+		  AND r.id <= ` + target + `
+		  AND NOT EXISTS (
+			SELECT 1 FROM cluster_runs u
+			WHERE u.kind = $5 AND u.status IN ($3, $4)
+			  AND u.id > r.id AND u.id <= ` + target + `
+			  AND (u.params->>'target_run_id')::bigint < r.id
+		  )
+		ORDER BY x.document_id, x.run_id DESC`, nil
+}
+
+// ClusteringExists reports whether the assignments of a run or generation are
+// still stored. They go away with the clusters they point at, when a
+// rollback drops a later run's clusters or a retired generation is pruned.
+func (r *ClusterRepo) ClusteringExists(ctx context.Context, by string, id int64) (bool, error) {
+	if r.pool == nil {
+		return false, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	var query string
+	switch by {
+	case cluster.CompareByRun:
+		query = `
+			SELECT generation_id IS NOT NULL
+			   AND (docs_processed = 0 OR EXISTS (SELECT 1 FROM cluster_assignments WHERE run_id = $1))
+			FROM cluster_runs WHERE id = $1`
+	case cluster.CompareByGeneration:
+		query = `
+			SELECT EXISTS (
+				SELECT 1 FROM cluster_assignments x
+				JOIN cluster_runs r ON r.id = x.run_id
+				WHERE r.generation_id = $1
+			)`
+	default:
+		return false, fmt.Errorf("cluster repo: unknown comparison %q", by)
+	}
+
+	var exists bool
+	err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("clustering of %s %d: %w", by, id, err)
+	}
+	return exists, nil
+}
+
+// Overlap returns the contingency table of the clusterings after runs (or
+// generations) a and b over the documents both assigned.
+func (r *ClusterRepo) Overlap(ctx context.Context, by string, a, b int64) ([]cluster.OverlapCell, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+	snapshotA, err := snapshot(by, "$1")
+	if err != nil {
+		return nil, fmt.Errorf("cluster overlap: %w", err)
+	}
+	snapshotB, err := snapshot(by, "$2")
+	if err != nil {
+		return nil, fmt.Errorf("cluster overlap: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, `
+		WITH a AS (`+snapshotA+`), b AS (`+snapshotB+`)
+		SELECT a.cluster_id, b.cluster_id, COUNT(*)
+		FROM a JOIN b ON b.document_id = a.document_id
+		GROUP BY a.cluster_id, b.cluster_id
+		ORDER BY a.cluster_id NULLS FIRST, b.cluster_id NULLS FIRST`,
+		a, b, cluster.RunStatusSucceeded, cluster.RunStatusRolledBack, cluster.RunKindRollback,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cluster overlap: %w", err)
+	}
+	defer rows.Close()
+
+	var out []cluster.OverlapCell
+	for rows.Next() {
+		var c cluster.OverlapCell
+		if err := rows.Scan(&c.A, &c.B, &c.Docs); err != nil {
+			return nil, fmt.Errorf("scan cluster overlap: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cluster overlap: %w", err)
+	}
+	return out, nil
+}
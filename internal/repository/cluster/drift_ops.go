@@ -0,0 +1,197 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/pgvector/pgvector-go"
+)
+
+// Get returns a cluster of any generation by id.
+func (r *ClusterRepo) Get(ctx context.Context, id int64) (cluster.Cluster, error) {
+	if r.pool == nil {
+		return cluster.Cluster{}, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select(clusterColumns...).
+		From("clusters c").
+		LeftJoin("documents d ON d.cluster_id = c.id").
+		Where(sq.Eq{"c.id": id}).
+		GroupBy("c.id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return cluster.Cluster{}, fmt.Errorf("build get cluster: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return cluster.Cluster{}, fmt.Errorf("get cluster: %w", err)
+	}
+	clusters, err := scanClusters(rows)
+	if err != nil {
+		return cluster.Cluster{}, err
+	}
+	if len(clusters) == 0 {
+		return cluster.Cluster{}, fmt.Errorf("get cluster %d: %w", id, pgx.ErrNoRows)
+	}
+	return clusters[0], nil
+}
+
+// CentroidStats averages the embeddings of every non-empty cluster of the
+// active generation with pgvector's AVG.
+func (r *ClusterRepo) CentroidStats(ctx context.Context) ([]cluster.CentroidStat, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("c.id", "c.centroid", "AVG(d.embedding)", "COUNT(d.id)").
+		From("clusters c").
+		Join("documents d ON d.cluster_id = c.id").
+		Where("c.generation_id = " + activeGeneration).
+		GroupBy("c.id").
+		OrderBy("c.id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build centroid stats: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("centroid stats: %w", err)
+	}
+	defer rows.Close()
+
+	var out []cluster.CentroidStat
+	for rows.Next() {
+		var stat cluster.CentroidStat
+		var centroid, mean pgvector.Vector
+		if err := rows.Scan(&stat.ClusterID, &centroid, &mean, &stat.Size); err != nil {
+			return nil, fmt.Errorf("scan centroid stat: %w", err)
+		}
+		stat.Centroid = centroid.Slice()
+		stat.Mean = mean.Slice()
+		out = append(out, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate centroid stats: %w", err)
+	}
+	return out, nil
+}
+
+// UpdateCentroids moves centroids and appends their drift to the history in
+// one transaction, one statement each for the whole batch.
+func (r *ClusterRepo) UpdateCentroids(ctx context.Context, updates []cluster.CentroidUpdate) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(updates))
+	centroids := make([]string, len(updates))
+	drifts := make([]float64, len(updates))
+	sizes := make([]int64, len(updates))
+	for i, u := range updates {
+		ids[i] = u.ClusterID
+		centroids[i] = pgvector.NewVector(u.Centroid).String()
+		drifts[i] = u.Drift
+		sizes[i] = u.Size
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin update centroids: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE clusters c
+		SET centroid = v.centroid::vector, drift = v.drift, recomputed_at = now(), updated_at = now()
+		FROM unnest($1::bigint[], $2::text[], $3::float8[]) AS v(id, centroid, drift)
+		WHERE c.id = v.id`,
+		ids, centroids, drifts,
+	); err != nil {
+		return fmt.Errorf("update centroids: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO cluster_centroid_history (cluster_id, drift, size)
+		SELECT id, drift, size FROM unnest($1::bigint[], $2::float8[], $3::bigint[]) AS v(id, drift, size)`,
+		ids, drifts, sizes,
+	); err != nil {
+		return fmt.Errorf("record centroid drift: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit update centroids: %w", err)
+	}
+	return nil
+}
+
+// PruneDriftHistory keeps the keep most recent drift points of every cluster
+// and deletes the rest.
+func (r *ClusterRepo) PruneDriftHistory(ctx context.Context, keep int) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tag, err := r.conn(ctx).Exec(ctx, `
+		DELETE FROM cluster_centroid_history
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY cluster_id ORDER BY created_at DESC, id DESC) AS rn
+				FROM cluster_centroid_history
+			) h
+			WHERE h.rn > $1
+		)`,
+		keep,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("prune drift history: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *ClusterRepo) DriftHistory(ctx context.Context, clusterID int64, limit int) ([]cluster.DriftPoint, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("drift", "size", "created_at").
+		From("cluster_centroid_history").
+		Where(sq.Eq{"cluster_id": clusterID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build drift history: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("drift history: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]cluster.DriftPoint, 0, limit)
+	for rows.Next() {
+		var p cluster.DriftPoint
+		if err := rows.Scan(&p.Drift, &p.Size, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan drift point: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate drift history: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,159 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+)
+
+const activeGeneration = "(SELECT id FROM cluster_generations WHERE status = 'active')"
+
+// CreateGeneration opens a staging generation for runID. Clusters created with
+// its id stay invisible to readers until ActivateGeneration.
+func (r *ClusterRepo) CreateGeneration(ctx context.Context, runID int64) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin create generation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO cluster_generations (run_id, status) VALUES ($1, $2) RETURNING id`,
+		runID, cluster.GenerationStatusStaging,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("insert cluster generation: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE cluster_runs SET generation_id = $1 WHERE id = $2`, id, runID); err != nil {
+		return 0, fmt.Errorf("link cluster generation to run: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit create generation: %w", err)
+	}
+	return id, nil
+}
+
+// ActivateGeneration makes a staging generation live in one transaction:
+// documents take the assignments runID staged for them, documents the run did
+// not see (inserted while it was fitting) are released for the worker, and
+// the previously active generation is retired.
+func (r *ClusterRepo) ActivateGeneration(ctx context.Context, generationID, runID int64) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin activate generation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM cluster_generations WHERE id = $1 FOR UPDATE`, generationID).Scan(&status); err != nil {
+		return 0, fmt.Errorf("activate generation: load generation %d: %w", generationID, err)
+	}
+	if status != cluster.GenerationStatusStaging {
+		return 0, fmt.Errorf("activate generation: generation %d has status %s", generationID, status)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE documents d
+		SET cluster_id = a.cluster_id, noise = a.cluster_id IS NULL, updated_at = now()
+		FROM cluster_assignments a
+		WHERE a.run_id = $1 AND a.document_id = d.id`,
+		runID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("activate generation: apply assignments: %w", err)
+	}
+	moved := tag.RowsAffected()
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE documents d
+		SET cluster_id = NULL, noise = false, updated_at = now()
+		WHERE (d.cluster_id IS NOT NULL OR d.noise)
+		  AND NOT EXISTS (SELECT 1 FROM cluster_assignments a WHERE a.run_id = $1 AND a.document_id = d.id)`,
+		runID,
+	); err != nil {
+		return 0, fmt.Errorf("activate generation: release unseen documents: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE cluster_generations SET status = $1, retired_at = now() WHERE status = $2`,
+		cluster.GenerationStatusRetired, cluster.GenerationStatusActive,
+	); err != nil {
+		return 0, fmt.Errorf("activate generation: retire previous: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE cluster_generations SET status = $1, activated_at = now() WHERE id = $2`,
+		cluster.GenerationStatusActive, generationID,
+	); err != nil {
+		return 0, fmt.Errorf("activate generation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit activate generation: %w", err)
+	}
+	return moved, nil
+}
+
+// DiscardGeneration drops the clusters of a staging generation that will
+// never be activated.
+func (r *ClusterRepo) DiscardGeneration(ctx context.Context, generationID int64) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin discard generation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM clusters WHERE generation_id = $1`, generationID); err != nil {
+		return fmt.Errorf("discard generation: delete clusters: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE cluster_generations SET status = $1, retired_at = now() WHERE id = $2 AND status = $3`,
+		cluster.GenerationStatusFailed, generationID, cluster.GenerationStatusStaging,
+	); err != nil {
+		return fmt.Errorf("discard generation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit discard generation: %w", err)
+	}
+	return nil
+}
+
+// PruneGenerations deletes every retired generation but the keep most
+// recently retired, together with their clusters and projections. Runs of a
+// deleted generation lose their generation and can no longer be rolled back
+// to.
+func (r *ClusterRepo) PruneGenerations(ctx context.Context, keep int) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tag, err := r.conn(ctx).Exec(ctx, `
+		DELETE FROM cluster_generations
+		WHERE status = $1
+		  AND id NOT IN (
+			SELECT id FROM cluster_generations
+			WHERE status = $1
+			ORDER BY retired_at DESC NULLS LAST, id DESC
+			LIMIT $2
+		  )`,
+		cluster.GenerationStatusRetired, keep,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("prune cluster generations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveLabels stores generated labels. Overrides are kept as they are.
+func (r *ClusterRepo) SaveLabels(ctx context.Context, labels []cluster.Label) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, l := range labels {
+		batch.Queue(`UPDATE clusters SET label = $1, keywords = $2, updated_at = now() WHERE id = $3`, l.Label, l.Keywords, l.ClusterID)
+	}
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin save labels: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("save labels: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit save labels: %w", err)
+	}
+	return nil
+}
+
+// PatchLabel sets or clears the manual label and keywords of cluster id.
+func (r *ClusterRepo) PatchLabel(ctx context.Context, id int64, patch cluster.LabelPatch) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	update := sq.
+		Update("clusters").
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+	if patch.Label != nil {
+		var label any
+		if *patch.Label != "" {
+			label = *patch.Label
+		}
+		update = update.Set("label_override", label)
+	}
+	if patch.Keywords != nil {
+		var keywords any
+		if len(*patch.Keywords) > 0 {
+			keywords = *patch.Keywords
+		}
+		update = update.Set("keywords_override", keywords)
+	}
+
+	query, args, err := update.ToSql()
+	if err != nil {
+		return fmt.Errorf("build patch label: %w", err)
+	}
+	tag, err := r.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("patch label: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("patch label of cluster %d: %w", id, pgx.ErrNoRows)
+	}
+	return nil
+}
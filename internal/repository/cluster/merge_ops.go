@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pgvector/pgvector-go"
+)
+
+// MergeClusters moves every document of source into target, records the moves
+// as assignments of runID and retires source. It returns how many documents
+// moved.
+func (r *ClusterRepo) MergeClusters(ctx context.Context, runID, sourceID, targetID int64) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin merge clusters: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO cluster_assignments (run_id, document_id, cluster_id)
+		SELECT $1, id, $3 FROM documents WHERE cluster_id = $2`,
+		runID, sourceID, targetID,
+	); err != nil {
+		return 0, fmt.Errorf("merge clusters: save assignments: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE documents SET cluster_id = $2, updated_at = now() WHERE cluster_id = $1`,
+		sourceID, targetID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("merge clusters: move documents: %w", err)
+	}
+	moved := tag.RowsAffected()
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE clusters SET merged_into = $2, merged_by_run = $3, updated_at = now() WHERE id = $1`,
+		sourceID, targetID, runID,
+	); err != nil {
+		return 0, fmt.Errorf("merge clusters: retire cluster %d: %w", sourceID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit merge clusters: %w", err)
+	}
+	return moved, nil
+}
+
+// CentroidStat averages the embeddings of one cluster like CentroidStats.
+func (r *ClusterRepo) CentroidStat(ctx context.Context, clusterID int64) (cluster.CentroidStat, error) {
+	if r.pool == nil {
+		return cluster.CentroidStat{}, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("c.id", "c.centroid", "AVG(d.embedding)", "COUNT(d.id)").
+		From("clusters c").
+		Join("documents d ON d.cluster_id = c.id").
+		Where(sq.Eq{"c.id": clusterID}).
+		GroupBy("c.id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return cluster.CentroidStat{}, fmt.Errorf("build centroid stat: %w", err)
+	}
+
+	var stat cluster.CentroidStat
+	var centroid, mean pgvector.Vector
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&stat.ClusterID, &centroid, &mean, &stat.Size); err != nil {
+		return cluster.CentroidStat{}, fmt.Errorf("centroid stat of cluster %d: %w", clusterID, err)
+	}
+	stat.Centroid = centroid.Slice()
+	stat.Mean = mean.Slice()
+	return stat, nil
+}
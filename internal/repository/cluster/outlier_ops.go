@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScoreOutliers stores each clustered document's distance to its centroid,
+// in the cluster's metric, and flags documents more than z standard
+// deviations above their cluster's mean distance. Unclustered documents lose
+// their score; noise counts as an outlier.
+func (r *ClusterRepo) ScoreOutliers(ctx context.Context, z float64) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin score outliers: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		WITH s AS (
+			SELECT d.id, d.cluster_id,
+			       CASE c.metric
+			           WHEN 'l2' THEN d.embedding <-> c.centroid
+			           WHEN 'inner_product' THEN d.embedding <#> c.centroid
+			           ELSE d.embedding <=> c.centroid
+			       END AS dist
+			FROM documents d
+			JOIN clusters c ON c.id = d.cluster_id
+		), t AS (
+			SELECT cluster_id, AVG(dist) AS mean, COALESCE(STDDEV_POP(dist), 0) AS sd
+			FROM s
+			GROUP BY cluster_id
+		)
+		UPDATE documents d
+		SET outlier_score = s.dist, is_outlier = s.dist > t.mean + $1 * t.sd
+		FROM s JOIN t ON t.cluster_id = s.cluster_id
+		WHERE d.id = s.id`,
+		z,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("score outliers: %w", err)
+	}
+	scored := tag.RowsAffected()
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE documents SET outlier_score = NULL, is_outlier = noise
+		WHERE cluster_id IS NULL AND (outlier_score IS NOT NULL OR is_outlier <> noise)`,
+	); err != nil {
+		return 0, fmt.Errorf("reset unclustered outliers: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit score outliers: %w", err)
+	}
+	return scored, nil
+}
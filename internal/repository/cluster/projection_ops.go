@@ -0,0 +1,53 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveProjection stores a projection fitted by p.RunID. A nil GenerationID
+// attaches it to the active generation.
+func (r *ClusterRepo) SaveProjection(ctx context.Context, p cluster.Projection) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, `
+		INSERT INTO cluster_projections (run_id, generation_id, dims, source_dims, explained_variance, mean, components)
+		VALUES ($1, COALESCE($2, `+activeGeneration+`), $3, $4, $5, $6, $7)
+		RETURNING id`,
+		p.RunID, p.GenerationID, p.Dims, p.SourceDims, p.ExplainedVariance, p.Mean, p.Components,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("insert cluster projection: %w", err)
+	}
+	return id, nil
+}
+
+// ActiveProjection returns the latest projection of the active generation, or
+// nil when its clusters were fitted without one.
+func (r *ClusterRepo) ActiveProjection(ctx context.Context) (*cluster.Projection, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	var p cluster.Projection
+	err := r.conn(ctx).QueryRow(ctx, `
+		SELECT id, COALESCE(run_id, 0), generation_id, dims, source_dims, explained_variance, mean, components, created_at
+		FROM cluster_projections
+		WHERE generation_id = `+activeGeneration+`
+		ORDER BY id DESC
+		LIMIT 1`,
+	).Scan(&p.ID, &p.RunID, &p.GenerationID, &p.Dims, &p.SourceDims, &p.ExplainedVariance, &p.Mean, &p.Components, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("active cluster projection: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,359 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *ClusterRepo) CreateRun(ctx context.Context, run cluster.Run) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Insert("cluster_runs").
+		Columns("kind", "algorithm", "metric", "k", "seed", "generation_id", "params", "status").
+		Values(
+			run.Kind,
+			run.Algorithm,
+			run.Metric,
+			run.K,
+			run.Seed,
+			sq.Expr(activeGeneration),
+			jsonOrEmpty(run.Params),
+			cluster.RunStatusRunning,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build insert cluster run: %w", err)
+	}
+
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("insert cluster run: %w", err)
+	}
+	return id, nil
+}
+
+func (r *ClusterRepo) FinishRun(ctx context.Context, run cluster.Run) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Update("cluster_runs").
+		Set("status", run.Status).
+		Set("error", run.Error).
+		Set("k", run.K).
+		Set("docs_processed", run.DocsProcessed).
+		Set("diagnostics", jsonOrEmpty(run.Diagnostics)).
+		Set("finished_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": run.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build finish cluster run: %w", err)
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("finish cluster run: %w", err)
+	}
+	return nil
+}
+
+func (r *ClusterRepo) GetRun(ctx context.Context, id int64) (cluster.Run, error) {
+	if r.pool == nil {
+		return cluster.Run{}, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := runSelect().
+		Where(sq.Eq{"r.id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("build get cluster run: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("get cluster run: %w", err)
+	}
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	if len(runs) == 0 {
+		return cluster.Run{}, fmt.Errorf("get cluster run %d: %w", id, pgx.ErrNoRows)
+	}
+	return runs[0], nil
+}
+
+func (r *ClusterRepo) ListRuns(ctx context.Context, limit, offset int) ([]cluster.Run, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	query, args, err := runSelect().
+		OrderBy("r.id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list cluster runs: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list cluster runs: %w", err)
+	}
+	return scanRuns(rows)
+}
+
+// SaveAssignments records which cluster a run put documents into. A nil
+// clusterID records the documents as noise.
+func (r *ClusterRepo) SaveAssignments(ctx context.Context, runID int64, docIDs []int64, clusterID *int64) error {
+	if r.pool == nil {
+		return fmt.Errorf("cluster repo: pool is nil")
+	}
+	if len(docIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sq.
+		Insert("cluster_assignments").
+		Columns("run_id", "document_id", "cluster_id").
+		Select(sq.Select().
+			Column("?::bigint", runID).
+			Column("unnest(?::bigint[])", docIDs).
+			Column("?::bigint", clusterID)).
+		Suffix("ON CONFLICT (run_id, document_id) DO UPDATE SET cluster_id = EXCLUDED.cluster_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build save cluster assignments: %w", err)
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("save cluster assignments: %w", err)
+	}
+	return nil
+}
+
+// Rollback restores every document to the assignment it had when targetID
+// finished: the latest assignment recorded by a succeeded run of the same
+// generation up to and including targetID, or unclustered if there is none.
+// Later runs are marked rolled back, their clusters and projections are
+// dropped and the target's generation becomes the active one again.
+// Centroids the later runs moved are reset to the mean of their restored
+// members. The restored state is recorded under rollbackID so that it can
+// itself be rolled back to.
+func (r *ClusterRepo) Rollback(ctx context.Context, rollbackID, targetID int64) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("cluster repo: pool is nil")
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin rollback: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var generationID *int64
+	if err := tx.QueryRow(ctx,
+		`SELECT status, generation_id FROM cluster_runs WHERE id = $1 FOR UPDATE`, targetID,
+	).Scan(&status, &generationID); err != nil {
+		return 0, fmt.Errorf("rollback: load run %d: %w", targetID, err)
+	}
+	if status != cluster.RunStatusSucceeded {
+		return 0, fmt.Errorf("rollback: run %d has status %s", targetID, status)
+	}
+	if generationID == nil {
+		return 0, fmt.Errorf("rollback: run %d has no generation", targetID)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO cluster_assignments (run_id, document_id, cluster_id)
+		SELECT DISTINCT ON (a.document_id) $1::bigint, a.document_id, a.cluster_id
+		FROM cluster_assignments a
+		JOIN cluster_runs r ON r.id = a.run_id
+		WHERE a.run_id <= $2 AND r.status = $3 AND r.generation_id = $4
+		ORDER BY a.document_id, a.run_id DESC`,
+		rollbackID, targetID, cluster.RunStatusSucceeded, *generationID,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: snapshot assignments: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE cluster_runs SET status = $1
+		WHERE id > $2 AND id <> $3 AND status = $4`,
+		cluster.RunStatusRolledBack, targetID, rollbackID, cluster.RunStatusSucceeded,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: mark runs: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE clusters SET merged_into = NULL, merged_by_run = NULL WHERE merged_by_run > $1`, targetID,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: undo merges: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM clusters c
+		USING cluster_runs r
+		WHERE c.run_id = r.id AND r.id > $1 AND r.status IN ($2, $3)`,
+		targetID, cluster.RunStatusRolledBack, cluster.RunStatusFailed,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: drop clusters: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM cluster_projections p
+		USING cluster_runs r
+		WHERE p.run_id = r.id AND r.id > $1 AND r.status IN ($2, $3)`,
+		targetID, cluster.RunStatusRolledBack, cluster.RunStatusFailed,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: drop projections: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE cluster_generations SET status = $1, retired_at = now() WHERE status = $2 AND id <> $3`,
+		cluster.GenerationStatusRetired, cluster.GenerationStatusActive, *generationID,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: retire generation: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE cluster_generations SET status = $1, activated_at = now(), retired_at = NULL WHERE id = $2 AND status <> $1`,
+		cluster.GenerationStatusActive, *generationID,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: activate generation: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE cluster_runs SET generation_id = $1 WHERE id = $2`, *generationID, rollbackID); err != nil {
+		return 0, fmt.Errorf("rollback: link generation: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE documents d
+		SET cluster_id = a.cluster_id, noise = a.cluster_id IS NULL, updated_at = now()
+		FROM cluster_assignments a
+		WHERE a.run_id = $1 AND a.document_id = d.id
+		  AND (d.cluster_id IS DISTINCT FROM a.cluster_id OR d.noise <> (a.cluster_id IS NULL))`,
+		rollbackID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("rollback: restore assignments: %w", err)
+	}
+	restored := tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, `
+		UPDATE documents d
+		SET cluster_id = NULL, noise = false, updated_at = now()
+		WHERE (d.cluster_id IS NOT NULL OR d.noise)
+		  AND NOT EXISTS (SELECT 1 FROM cluster_assignments a WHERE a.run_id = $1 AND a.document_id = d.id)`,
+		rollbackID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("rollback: reset documents: %w", err)
+	}
+	restored += tag.RowsAffected()
+
+	// Incremental runs and merges nudge the centroids of the clusters they
+	// assign to; those assignments survive unless their cluster was dropped.
+	if _, err := tx.Exec(ctx, `
+		UPDATE clusters c
+		SET centroid = CASE WHEN c.metric IN ('l2', 'inner_product') THEN m.mean ELSE l2_normalize(m.mean) END,
+		    updated_at = now()
+		FROM (
+			SELECT d.cluster_id, AVG(d.embedding) AS mean
+			FROM documents d
+			WHERE d.cluster_id IN (
+				SELECT a.cluster_id FROM cluster_assignments a
+				WHERE a.run_id > $1 AND a.run_id <> $2 AND a.cluster_id IS NOT NULL
+			)
+			GROUP BY d.cluster_id
+		) m
+		WHERE c.id = m.cluster_id`,
+		targetID, rollbackID,
+	); err != nil {
+		return 0, fmt.Errorf("rollback: recompute centroids: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit rollback: %w", err)
+	}
+	return restored, nil
+}
+
+func runSelect() sq.SelectBuilder {
+	return sq.
+		Select(
+			"r.id",
+			"r.kind",
+			"r.algorithm",
+			"r.metric",
+			"r.k",
+			"r.seed",
+			"r.generation_id",
+			"r.params",
+			"r.diagnostics",
+			"r.status",
+			"r.error",
+			"r.docs_processed",
+			"q.inertia",
+			"q.silhouette",
+			"q.davies_bouldin",
+			"r.started_at",
+			"r.finished_at",
+		).
+		From("cluster_runs r").
+		LeftJoin("cluster_quality q ON q.run_id = r.id")
+}
+
+func scanRuns(rows pgx.Rows) ([]cluster.Run, error) {
+	defer rows.Close()
+
+	var out []cluster.Run
+	for rows.Next() {
+		var run cluster.Run
+		if err := rows.Scan(
+			&run.ID,
+			&run.Kind,
+			&run.Algorithm,
+			&run.Metric,
+			&run.K,
+			&run.Seed,
+			&run.GenerationID,
+			&run.Params,
+			&run.Diagnostics,
+			&run.Status,
+			&run.Error,
+			&run.DocsProcessed,
+			&run.Inertia,
+			&run.Silhouette,
+			&run.DaviesBouldin,
+			&run.StartedAt,
+			&run.FinishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan cluster run: %w", err)
+		}
+		out = append(out, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cluster runs: %w", err)
+	}
+	return out, nil
+}
+
+func jsonOrEmpty(raw []byte) string {
+	if len(raw) == 0 {
+		return "{}"
+	}
+	return string(raw)
+}
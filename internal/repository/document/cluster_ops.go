@@ -3,13 +3,20 @@ package document
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
+	"NeoBIT/internal/db"
 	"NeoBIT/internal/models/document"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/pgvector/pgvector-go"
 )
 
-func (r *DocumentRepo) ListUnclustered(ctx context.Context, limit int) ([]document.Document, error) {
+// ClaimUnclustered leases up to limit unclustered documents for lease. Rows
+// locked or leased by another worker are skipped, so concurrent instances
+// never receive the same batch. The lease is cleared when the document is
+// assigned or marked as noise; otherwise it simply expires.
+func (r *DocumentRepo) ClaimUnclustered(ctx context.Context, limit int, lease time.Duration) ([]document.Document, error) {
 	if r.pool == nil {
 		return nil, fmt.Errorf("document repo: pool is nil")
 	}
@@ -17,34 +24,42 @@ func (r *DocumentRepo) ListUnclustered(ctx context.Context, limit int) ([]docume
 		limit = 1000
 	}
 
-	query, args, err := sq.
-		Select(
-			"id",
-			"COALESCE(hn_id, 0) AS hn_id",
-			"COALESCE(title, '') AS title",
-			"COALESCE(url, '') AS url",
-			"COALESCE(by, '') AS by",
-			"COALESCE(score, 0) AS score",
-			"COALESCE(time, now()) AS time",
-			"COALESCE(text, '') AS text",
-			"embedding",
-			"cluster_id",
-			"created_at",
-			"updated_at",
-		).
+	claimable := sq.
+		Select("id").
 		From("documents").
-		Where("cluster_id IS NULL").
+		Where("cluster_id IS NULL AND NOT noise").
+		Where("(leased_until IS NULL OR leased_until < now())").
 		OrderBy("id ASC").
 		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	query, args, err := sq.
+		Update("documents").
+		Set("leased_until", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Where(sq.Expr("id IN (?)", claimable)).
+		Suffix(`RETURNING
+			id,
+			COALESCE(hn_id, 0) AS hn_id,
+			COALESCE(title, '') AS title,
+			COALESCE(url, '') AS url,
+			COALESCE(by, '') AS by,
+			COALESCE(score, 0) AS score,
+			COALESCE(time, now()) AS time,
+			COALESCE(text, '') AS text,
+			embedding,
+			cluster_id,
+			noise,
+			created_at,
+			updated_at`).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("build list unclustered: %w", err)
+		return nil, fmt.Errorf("build claim unclustered: %w", err)
 	}
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("list unclustered documents: %w", err)
+		return nil, fmt.Errorf("claim unclustered documents: %w", err)
 	}
 	defer rows.Close()
 
@@ -63,6 +78,7 @@ func (r *DocumentRepo) ListUnclustered(ctx context.Context, limit int) ([]docume
 			&doc.Text,
 			&embedding,
 			&doc.ClusterID,
+			&doc.Noise,
 			&doc.CreatedAt,
 			&doc.UpdatedAt,
 		); err != nil {
@@ -74,9 +90,35 @@ func (r *DocumentRepo) ListUnclustered(ctx context.Context, limit int) ([]docume
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate unclustered documents: %w", err)
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
 	return out, nil
 }
 
+// ReleaseLeases hands claimed documents back before their lease expires.
+func (r *DocumentRepo) ReleaseLeases(ctx context.Context, ids []int64) error {
+	if r.pool == nil {
+		return fmt.Errorf("document repo: pool is nil")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := sq.
+		Update("documents").
+		Set("leased_until", nil).
+		Where(sq.Eq{"id": ids}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build release leases: %w", err)
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("release leases: %w", err)
+	}
+	return nil
+}
+
 func (r *DocumentRepo) UpdateClusterIDs(ctx context.Context, ids []int64, clusterID int64) error {
 	if r.pool == nil {
 		return fmt.Errorf("document repo: pool is nil")
@@ -88,6 +130,8 @@ func (r *DocumentRepo) UpdateClusterIDs(ctx context.Context, ids []int64, cluste
 	query, args, err := sq.
 		Update("documents").
 		Set("cluster_id", clusterID).
+		Set("noise", false).
+		Set("leased_until", nil).
 		Set("updated_at", sq.Expr("now()")).
 		Where(sq.Eq{"id": ids}).
 		PlaceholderFormat(sq.Dollar).
@@ -96,7 +140,7 @@ func (r *DocumentRepo) UpdateClusterIDs(ctx context.Context, ids []int64, cluste
 		return fmt.Errorf("build update cluster ids: %w", err)
 	}
 
-	_, err = r.pool.Exec(ctx, query, args...)
+	_, err = r.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("update cluster ids: %w", err)
 	}
@@ -118,9 +162,361 @@ func (r *DocumentRepo) PctClustered(ctx context.Context) (float64, error) {
 		return 0, fmt.Errorf("document repo: build pct clustered: %w", err)
 	}
 
-	err = r.pool.QueryRow(ctx, query, args...).Scan(&pct)
+	err = r.conn(ctx).QueryRow(ctx, query, args...).Scan(&pct)
 	if err != nil {
 		return 0, fmt.Errorf("document repo: pct clustered: %w", err)
 	}
 	return pct, nil
 }
+
+func (r *DocumentRepo) CountUnclustered(ctx context.Context) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("document repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("COUNT(*)").
+		From("documents").
+		Where("cluster_id IS NULL AND NOT noise").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("document repo: build count unclustered: %w", err)
+	}
+
+	var count int64
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("document repo: count unclustered: %w", err)
+	}
+	return count, nil
+}
+
+func (r *DocumentRepo) SampleEmbeddings(ctx context.Context, fraction float64, limit int) ([][]float32, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	pct := fraction * 100
+	if pct <= 0 || pct > 100 {
+		pct = 100
+	}
+
+	query, args, err := sq.
+		Select("embedding").
+		From(fmt.Sprintf("documents TABLESAMPLE BERNOULLI (%f)", pct)).
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("document repo: build sample embeddings: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: sample embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([][]float32, 0, limit)
+	for rows.Next() {
+		var embedding pgvector.Vector
+		if err := rows.Scan(&embedding); err != nil {
+			return nil, fmt.Errorf("document repo: scan sampled embedding: %w", err)
+		}
+		out = append(out, embedding.Slice())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate sampled embeddings: %w", err)
+	}
+	return out, nil
+}
+
+func (r *DocumentRepo) MarkNoise(ctx context.Context, ids []int64) error {
+	if r.pool == nil {
+		return fmt.Errorf("document repo: pool is nil")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := sq.
+		Update("documents").
+		Set("noise", true).
+		Set("cluster_id", nil).
+		Set("leased_until", nil).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": ids}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build mark noise: %w", err)
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("mark noise: %w", err)
+	}
+	return nil
+}
+
+// CountNeighbors returns how many documents lie within radius of embedding,
+// capped at limit. The inner ORDER BY ... LIMIT is served by the HNSW index.
+func (r *DocumentRepo) CountNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) (int, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("document repo: pool is nil")
+	}
+	if limit <= 0 {
+		return 0, nil
+	}
+	op := db.DistanceOperator(metric)
+	vec := pgvector.NewVector(embedding)
+
+	knn := sq.
+		Select().
+		Column(sq.Expr("embedding "+op+" ? AS dist", vec)).
+		From("documents").
+		OrderBy("dist").
+		Limit(uint64(limit))
+
+	query, args, err := sq.
+		Select("COUNT(*)").
+		FromSelect(knn, "n").
+		Where(sq.LtOrEq{"dist": radius}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("document repo: build count neighbors: %w", err)
+	}
+
+	var count int
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("document repo: count neighbors: %w", err)
+	}
+	return count, nil
+}
+
+// ClusteredNeighbors returns the id, cluster and embedding of up to limit
+// clustered documents within radius of embedding, nearest first. Like
+// CountNeighbors, the inner ORDER BY ... LIMIT is served by the HNSW index,
+// so only the nearest limit documents of the whole table are considered.
+func (r *DocumentRepo) ClusteredNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) ([]document.Document, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	op := db.DistanceOperator(metric)
+	vec := pgvector.NewVector(embedding)
+
+	knn := sq.
+		Select("id", "cluster_id", "embedding").
+		Column(sq.Expr("embedding "+op+" ? AS dist", vec)).
+		From("documents").
+		OrderBy("dist").
+		Limit(uint64(limit))
+
+	query, args, err := sq.
+		Select("id", "cluster_id", "embedding").
+		FromSelect(knn, "n").
+		Where(sq.LtOrEq{"dist": radius}).
+		Where("cluster_id IS NOT NULL").
+		OrderBy("dist").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("document repo: build clustered neighbors: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: clustered neighbors: %w", err)
+	}
+	defer rows.Close()
+
+	var out []document.Document
+	for rows.Next() {
+		var doc document.Document
+		var neighbor pgvector.Vector
+		if err := rows.Scan(&doc.ID, &doc.ClusterID, &neighbor); err != nil {
+			return nil, fmt.Errorf("document repo: scan clustered neighbor: %w", err)
+		}
+		doc.Embedding = neighbor.Slice()
+		out = append(out, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate clustered neighbors: %w", err)
+	}
+	return out, nil
+}
+
+// ListEmbeddingsAfter pages through every document by id, returning only ids
+// and embeddings.
+func (r *DocumentRepo) ListEmbeddingsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("id", "embedding").
+		From("documents").
+		Where(sq.Gt{"id": afterID}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("document repo: build list embeddings: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: list embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]document.Document, 0, limit)
+	for rows.Next() {
+		var doc document.Document
+		var embedding pgvector.Vector
+		if err := rows.Scan(&doc.ID, &embedding); err != nil {
+			return nil, fmt.Errorf("document repo: scan embedding: %w", err)
+		}
+		doc.Embedding = embedding.Slice()
+		out = append(out, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate embeddings: %w", err)
+	}
+	return out, nil
+}
+
+// ListLabelTexts returns titles and texts of up to perCluster top-scored
+// documents of every cluster, with ClusterID set.
+func (r *DocumentRepo) ListLabelTexts(ctx context.Context, perCluster int) ([]document.Document, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	ranked := sq.
+		Select(
+			"id",
+			"cluster_id",
+			"COALESCE(title, '') AS title",
+			"COALESCE(text, '') AS text",
+			"ROW_NUMBER() OVER (PARTITION BY cluster_id ORDER BY score DESC NULLS LAST, id) AS rn",
+		).
+		From("documents").
+		Where("cluster_id IS NOT NULL")
+
+	query, args, err := sq.
+		Select("id", "cluster_id", "title", "text").
+		FromSelect(ranked, "r").
+		Where(sq.LtOrEq{"rn": perCluster}).
+		OrderBy("cluster_id", "id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("document repo: build list label texts: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: list label texts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []document.Document
+	for rows.Next() {
+		var doc document.Document
+		if err := rows.Scan(&doc.ID, &doc.ClusterID, &doc.Title, &doc.Text); err != nil {
+			return nil, fmt.Errorf("document repo: scan label text: %w", err)
+		}
+		out = append(out, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate label texts: %w", err)
+	}
+	return out, nil
+}
+
+// ListTextsAfter returns up to limit clustered documents with id greater than
+// afterID, in id order, with ClusterID, title and text set.
+func (r *DocumentRepo) ListTextsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("id", "cluster_id", "COALESCE(title, '')", "COALESCE(text, '')").
+		From("documents").
+		Where(sq.Gt{"id": afterID}).
+		Where("cluster_id IS NOT NULL").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("document repo: build list texts: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: list texts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]document.Document, 0, limit)
+	for rows.Next() {
+		var doc document.Document
+		if err := rows.Scan(&doc.ID, &doc.ClusterID, &doc.Title, &doc.Text); err != nil {
+			return nil, fmt.Errorf("document repo: scan text: %w", err)
+		}
+		out = append(out, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate texts: %w", err)
+	}
+	return out, nil
+}
+
+// ListClusterEmbeddings returns the id and embedding of every document in a
+// cluster, in id order.
+func (r *DocumentRepo) ListClusterEmbeddings(ctx context.Context, clusterID int64) ([]document.Document, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select("id", "embedding").
+		From("documents").
+		Where(sq.Eq{"cluster_id": clusterID}).
+		OrderBy("id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("document repo: build list cluster embeddings: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: list cluster embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var out []document.Document
+	for rows.Next() {
+		var doc document.Document
+		var embedding pgvector.Vector
+		if err := rows.Scan(&doc.ID, &embedding); err != nil {
+			return nil, fmt.Errorf("document repo: scan cluster embedding: %w", err)
+		}
+		doc.Embedding = embedding.Slice()
+		out = append(out, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate cluster embeddings: %w", err)
+	}
+	return out, nil
+}
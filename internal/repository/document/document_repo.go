@@ -2,11 +2,14 @@ package document
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"NeoBIT/internal/db"
 	"NeoBIT/internal/logger"
 	"NeoBIT/internal/models/document"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pgvector/pgvector-go"
 )
@@ -23,6 +26,11 @@ func NewDocumentRepo(pool *pgxpool.Pool, log logger.Logger) *DocumentRepo {
 	return &DocumentRepo{pool: pool, log: log}
 }
 
+// conn joins the transaction carried by ctx, if any.
+func (r *DocumentRepo) conn(ctx context.Context) db.Querier {
+	return db.Conn(ctx, r.pool)
+}
+
 func (r *DocumentRepo) Create(ctx context.Context, doc document.Document) (int64, error) {
 	if r.pool == nil {
 		return 0, fmt.Errorf("document repo: pool is nil")
@@ -51,7 +59,7 @@ func (r *DocumentRepo) Create(ctx context.Context, doc document.Document) (int64
 	}
 
 	var id int64
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&id); err != nil {
 		return 0, fmt.Errorf("insert document: %w", err)
 	}
 	return id, nil
@@ -63,20 +71,7 @@ func (r *DocumentRepo) GetByID(ctx context.Context, id int64) (document.Document
 	}
 
 	query, args, err := sq.
-		Select(
-			"id",
-			"COALESCE(hn_id, 0) AS hn_id",
-			"COALESCE(title, '') AS title",
-			"COALESCE(url, '') AS url",
-			"COALESCE(by, '') AS by",
-			"COALESCE(score, 0) AS score",
-			"COALESCE(time, now()) AS time",
-			"COALESCE(text, '') AS text",
-			"embedding",
-			"cluster_id",
-			"created_at",
-			"updated_at",
-		).
+		Select(documentColumns...).
 		From("documents").
 		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar).
@@ -86,10 +81,36 @@ func (r *DocumentRepo) GetByID(ctx context.Context, id int64) (document.Document
 	}
 
 	var doc document.Document
-	var embedding pgvector.Vector
-	row := r.pool.QueryRow(ctx, query, args...)
+	if err := scanDocument(r.conn(ctx).QueryRow(ctx, query, args...), &doc); err != nil {
+		return document.Document{}, fmt.Errorf("get document: %w", err)
+	}
+	return doc, nil
+}
+
+var documentColumns = []string{
+	"id",
+	"COALESCE(hn_id, 0) AS hn_id",
+	"COALESCE(title, '') AS title",
+	"COALESCE(url, '') AS url",
+	"COALESCE(by, '') AS by",
+	"COALESCE(score, 0) AS score",
+	"COALESCE(time, now()) AS time",
+	"COALESCE(text, '') AS text",
+	"embedding",
+	"cluster_id",
+	"noise",
+	"is_outlier",
+	"outlier_score",
+	"duplicate_set",
+	"created_at",
+	"updated_at",
+}
 
-	if err := row.Scan(
+// scanDocument scans documentColumns into doc, followed by any extra
+// columns.
+func scanDocument(row pgx.Row, doc *document.Document, extra ...any) error {
+	var embedding pgvector.Vector
+	dest := []any{
 		&doc.ID,
 		&doc.HNID,
 		&doc.Title,
@@ -100,48 +121,61 @@ func (r *DocumentRepo) GetByID(ctx context.Context, id int64) (document.Document
 		&doc.Text,
 		&embedding,
 		&doc.ClusterID,
+		&doc.Noise,
+		&doc.IsOutlier,
+		&doc.OutlierScore,
+		&doc.DuplicateSet,
 		&doc.CreatedAt,
 		&doc.UpdatedAt,
-	); err != nil {
-		return document.Document{}, fmt.Errorf("get document: %w", err)
 	}
-
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
 	doc.Embedding = embedding.Slice()
-	return doc, nil
+	return nil
 }
 
-func (r *DocumentRepo) ListByCluster(ctx context.Context, clusterID int64, limit, offset int) ([]document.Document, error) {
+// ListByCluster pages through a cluster's documents in the given order (see
+// document.Order*). Centrality ranks by distance to the cluster centroid in
+// the cluster's metric; the HNSW index is built for cosine only, so l2 and
+// inner product clusters are sorted by a scan of the cluster's documents.
+func (r *DocumentRepo) ListByCluster(ctx context.Context, clusterID int64, limit, offset int, order string) ([]document.Document, error) {
 	if r.pool == nil {
 		return nil, fmt.Errorf("document repo: pool is nil")
 	}
 
-	query, args, err := sq.
-		Select(
-			"id",
-			"COALESCE(hn_id, 0) AS hn_id",
-			"COALESCE(title, '') AS title",
-			"COALESCE(url, '') AS url",
-			"COALESCE(by, '') AS by",
-			"COALESCE(score, 0) AS score",
-			"COALESCE(time, now()) AS time",
-			"COALESCE(text, '') AS text",
-			"embedding",
-			"cluster_id",
-			"created_at",
-			"updated_at",
-		).
+	list := sq.
+		Select(documentColumns...).
 		From("documents").
 		Where(sq.Eq{"cluster_id": clusterID}).
-		OrderBy("id ASC").
 		Limit(uint64(limit)).
 		Offset(uint64(offset)).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		PlaceholderFormat(sq.Dollar)
+
+	switch order {
+	case document.OrderCentrality:
+		metric, centroid, err := r.clusterCentroid(ctx, clusterID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = list.OrderByClause("embedding "+db.DistanceOperator(metric)+" ?, id", centroid)
+	case document.OrderScore:
+		list = list.OrderBy("score DESC NULLS LAST", "id")
+	case document.OrderTime:
+		list = list.OrderBy("time DESC NULLS LAST", "id")
+	default:
+		list = list.OrderBy("id ASC")
+	}
+
+	query, args, err := list.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build list documents: %w", err)
 	}
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list cluster documents: %w", err)
 	}
@@ -150,25 +184,9 @@ func (r *DocumentRepo) ListByCluster(ctx context.Context, clusterID int64, limit
 	var out []document.Document
 	for rows.Next() {
 		var doc document.Document
-		var embedding pgvector.Vector
-		if err := rows.Scan(
-			&doc.ID,
-			&doc.HNID,
-			&doc.Title,
-			&doc.URL,
-			&doc.By,
-			&doc.Score,
-			&doc.Time,
-			&doc.Text,
-			&embedding,
-			&doc.ClusterID,
-			&doc.CreatedAt,
-			&doc.UpdatedAt,
-		); err != nil {
+		if err := scanDocument(rows, &doc); err != nil {
 			return nil, fmt.Errorf("scan cluster document: %w", err)
 		}
-
-		doc.Embedding = embedding.Slice()
 		out = append(out, doc)
 	}
 	if err := rows.Err(); err != nil {
@@ -0,0 +1,127 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/document"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pgvector/pgvector-go"
+)
+
+// NearDuplicates returns the ids of up to limit documents other than id whose
+// cosine distance to embedding is below maxDistance. The inner ORDER BY ...
+// LIMIT is served by the HNSW index; the threshold is applied afterwards so
+// that the index stays usable.
+func (r *DocumentRepo) NearDuplicates(ctx context.Context, id int64, embedding []float32, maxDistance float64, limit int) ([]int64, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT id FROM (
+			SELECT id, embedding <=> $1 AS dist
+			FROM documents
+			ORDER BY embedding <=> $1
+			LIMIT $2
+		) n
+		WHERE dist < $3 AND id <> $4
+		ORDER BY dist`,
+		pgvector.NewVector(embedding), limit+1, maxDistance, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("document repo: near duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var dup int64
+		if err := rows.Scan(&dup); err != nil {
+			return nil, fmt.Errorf("document repo: scan near duplicate: %w", err)
+		}
+		out = append(out, dup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("document repo: iterate near duplicates: %w", err)
+	}
+	return out, nil
+}
+
+// SaveDuplicateSets stores the duplicate set of every document in sets and
+// clears it on all other documents, in one transaction.
+func (r *DocumentRepo) SaveDuplicateSets(ctx context.Context, sets map[int64]int64) error {
+	if r.pool == nil {
+		return fmt.Errorf("document repo: pool is nil")
+	}
+
+	ids := make([]int64, 0, len(sets))
+	setIDs := make([]int64, 0, len(sets))
+	for id, set := range sets {
+		ids = append(ids, id)
+		setIDs = append(setIDs, set)
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("document repo: begin save duplicate sets: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE documents SET duplicate_set = NULL WHERE duplicate_set IS NOT NULL AND NOT (id = ANY($1))`, ids,
+	); err != nil {
+		return fmt.Errorf("document repo: clear duplicate sets: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE documents d SET duplicate_set = v.set_id
+		FROM unnest($1::bigint[], $2::bigint[]) AS v(id, set_id)
+		WHERE d.id = v.id AND d.duplicate_set IS DISTINCT FROM v.set_id`,
+		ids, setIDs,
+	); err != nil {
+		return fmt.Errorf("document repo: save duplicate sets: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("document repo: commit duplicate sets: %w", err)
+	}
+	return nil
+}
+
+// Duplicates returns the other members of document id's duplicate set.
+func (r *DocumentRepo) Duplicates(ctx context.Context, id int64) ([]document.Document, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	query, args, err := sq.
+		Select(documentColumns...).
+		From("documents").
+		Where("duplicate_set = (SELECT duplicate_set FROM documents WHERE id = ?)", id).
+		Where(sq.NotEq{"id": id}).
+		OrderBy("id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list duplicates: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	var out []document.Document
+	for rows.Next() {
+		var doc document.Document
+		if err := scanDocument(rows, &doc); err != nil {
+			return nil, fmt.Errorf("scan duplicate: %w", err)
+		}
+		out = append(out, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate duplicates: %w", err)
+	}
+	return out, nil
+}
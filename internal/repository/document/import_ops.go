@@ -41,7 +41,7 @@ func (r *DocumentRepo) CreateBatch(ctx context.Context, docs []document.Document
 		return 0, fmt.Errorf("build insert batch documents: %w", err)
 	}
 
-	tag, err := r.pool.Exec(ctx, query, args...)
+	tag, err := r.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("insert batch documents: %w", err)
 	}
@@ -64,7 +64,7 @@ func (r *DocumentRepo) Count(ctx context.Context) (int64, error) {
 	}
 
 	var count int64
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+	if err := r.conn(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
 		return 0, fmt.Errorf("count documents: %w", err)
 	}
 	return count, nil
@@ -0,0 +1,105 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/document"
+	sq "github.com/Masterminds/squirrel"
+)
+
+// SaveMapCoordinates stores the map position of each document in ids.
+func (r *DocumentRepo) SaveMapCoordinates(ctx context.Context, ids []int64, xs, ys []float32) error {
+	if r.pool == nil {
+		return fmt.Errorf("document repo: pool is nil")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, `
+		UPDATE documents d SET map_x = v.x, map_y = v.y
+		FROM unnest($1::bigint[], $2::real[], $3::real[]) AS v(id, x, y)
+		WHERE d.id = v.id`,
+		ids, xs, ys,
+	); err != nil {
+		return fmt.Errorf("document repo: save map coordinates: %w", err)
+	}
+	return nil
+}
+
+// RankMapPoints sets map_rank of every placed document to its position
+// within its cluster over the cluster's size, so that reading in map_rank
+// order interleaves clusters proportionally. It returns the number of
+// documents whose rank changed.
+func (r *DocumentRepo) RankMapPoints(ctx context.Context) (int64, error) {
+	if r.pool == nil {
+		return 0, fmt.Errorf("document repo: pool is nil")
+	}
+
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE documents d SET map_rank = r.share
+		FROM (
+			SELECT id, ((ROW_NUMBER() OVER (PARTITION BY cluster_id ORDER BY id) - 0.5) / COUNT(*) OVER (PARTITION BY cluster_id))::float8 AS share
+			FROM documents
+			WHERE map_x IS NOT NULL
+		) r
+		WHERE d.id = r.id AND d.map_rank IS DISTINCT FROM r.share`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("document repo: rank map points: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// MapPoints returns up to limit placed documents. With clusterID set they are
+// the cluster's documents in id order; otherwise they are read in map_rank
+// order, so each cluster contributes in proportion to its size and the map
+// is not just the oldest documents. Only the columns the map draws are read.
+func (r *DocumentRepo) MapPoints(ctx context.Context, clusterID *int64, limit int) ([]document.MapPoint, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	var builder sq.SelectBuilder
+	if clusterID != nil {
+		builder = sq.
+			Select("id", "map_x", "map_y", "cluster_id", "COALESCE(title, '')").
+			From("documents").
+			Where("map_x IS NOT NULL").
+			Where(sq.Eq{"cluster_id": *clusterID}).
+			OrderBy("id")
+	} else {
+		builder = sq.
+			Select("id", "map_x", "map_y", "cluster_id", "COALESCE(title, '')").
+			From("documents").
+			Where("map_rank IS NOT NULL").
+			OrderBy("map_rank", "id")
+	}
+	query, args, err := builder.
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build map points: %w", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("map points: %w", err)
+	}
+	defer rows.Close()
+
+	var out []document.MapPoint
+	for rows.Next() {
+		var p document.MapPoint
+		if err := rows.Scan(&p.ID, &p.X, &p.Y, &p.ClusterID, &p.Title); err != nil {
+			return nil, fmt.Errorf("scan map point: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate map points: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"NeoBIT/internal/db"
+	"NeoBIT/internal/models/document"
+	"github.com/jackc/pgx/v5"
+	"github.com/pgvector/pgvector-go"
+)
+
+const (
+	// representativesOverfetch is how many nearest documents per requested
+	// one are read from the HNSW index when pgvector cannot scan it
+	// iteratively.
+	representativesOverfetch = 20
+	// maxEFSearch is the largest hnsw.ef_search pgvector accepts.
+	maxEFSearch = 1000
+)
+
+// Representatives returns the n documents of a cluster closest to its
+// centroid. Only cosine has an HNSW index, so l2 and inner product clusters
+// are ranked exactly by a scan of the cluster. For cosine the ORDER BY ...
+// LIMIT is served by the index, which alone returns only hnsw.ef_search
+// candidates before the cluster filter: pgvector 0.8+ keeps walking the
+// index with iterative scan until n rows pass the filter, while older
+// versions read a larger candidate list and filter it, which can still
+// return fewer than n documents for a small cluster.
+func (r *DocumentRepo) Representatives(ctx context.Context, clusterID int64, n int) ([]document.Representative, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("document repo: pool is nil")
+	}
+
+	metric, centroid, err := r.clusterCentroid(ctx, clusterID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin representatives: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	columns := strings.Join(documentColumns, ", ") + ", embedding " + db.DistanceOperator(metric) + " $2 AS dist"
+	query := `
+		SELECT ` + columns + `
+		FROM documents
+		WHERE cluster_id = $1
+		ORDER BY dist
+		LIMIT $3`
+	args := []any{clusterID, centroid, n}
+	if metric != "l2" && metric != "inner_product" {
+		iterative, err := iterativeScan(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		if iterative {
+			if _, err := tx.Exec(ctx, "SET LOCAL hnsw.iterative_scan = strict_order"); err != nil {
+				return nil, fmt.Errorf("enable iterative scan: %w", err)
+			}
+		} else {
+			candidates := min(n*representativesOverfetch, maxEFSearch)
+			if _, err := tx.Exec(ctx, "SET LOCAL hnsw.ef_search = "+strconv.Itoa(candidates)); err != nil {
+				return nil, fmt.Errorf("raise ef_search: %w", err)
+			}
+			query = `
+				SELECT * FROM (
+					SELECT ` + columns + `
+					FROM documents
+					ORDER BY dist
+					LIMIT $4
+				) c
+				WHERE cluster_id = $1
+				ORDER BY dist
+				LIMIT $3`
+			args = append(args, candidates)
+		}
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list representatives: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]document.Representative, 0, n)
+	for rows.Next() {
+		var rep document.Representative
+		if err := scanDocument(rows, &rep.Document, &rep.Distance); err != nil {
+			return nil, fmt.Errorf("scan representative: %w", err)
+		}
+		out = append(out, rep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate representatives: %w", err)
+	}
+	return out, nil
+}
+
+func (r *DocumentRepo) clusterCentroid(ctx context.Context, clusterID int64) (string, pgvector.Vector, error) {
+	var metric string
+	var centroid pgvector.Vector
+	err := r.conn(ctx).QueryRow(ctx, `SELECT metric, centroid FROM clusters WHERE id = $1`, clusterID).Scan(&metric, &centroid)
+	if err != nil {
+		return "", pgvector.Vector{}, fmt.Errorf("load centroid of cluster %d: %w", clusterID, err)
+	}
+	return metric, centroid, nil
+}
+
+// iterativeScan reports whether the installed pgvector supports
+// hnsw.iterative_scan, added in 0.8.
+func iterativeScan(ctx context.Context, tx pgx.Tx) (bool, error) {
+	var version string
+	err := tx.QueryRow(ctx, `SELECT extversion FROM pg_extension WHERE extname = 'vector'`).Scan(&version)
+	if err != nil {
+		return false, fmt.Errorf("load pgvector version: %w", err)
+	}
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false, nil
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false, nil
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, nil
+	}
+	return major > 0 || minor >= 8, nil
+}
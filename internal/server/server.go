@@ -16,6 +16,7 @@ import (
 	clusterservice "NeoBIT/internal/service/cluster"
 	documentservice "NeoBIT/internal/service/document"
 	importservice "NeoBIT/internal/service/importer"
+	adminhandler "NeoBIT/internal/transport/http/handler/admin"
 	clusterhandler "NeoBIT/internal/transport/http/handler/cluster"
 	documenthandler "NeoBIT/internal/transport/http/handler/document"
 	httpmiddleware "NeoBIT/internal/transport/http/middleware"
@@ -42,12 +43,23 @@ func Start(cfg config.Config, log logger.Logger) error {
 	docHandler := documenthandler.NewHandler(docSvc, log)
 
 	clusterRepo := clusterrepo.NewClusterRepo(pool, log)
-	clusterSvc := clusterservice.NewService(clusterRepo, docRepo, config.DefaultClusterConfig(), log)
+	clusterCfg := config.GetClusterConfig()
+	locker := db.NewLocker(pool)
+	clusterSvc, err := clusterservice.NewService(clusterRepo, docRepo, db.NewTransactor(pool), locker, clusterCfg, log)
+	if err != nil {
+		return err
+	}
 	clusterHandler := clusterhandler.NewHandler(clusterSvc, log)
+	clusterJobs := clusterservice.NewJobManager(ctx, clusterSvc, log)
+	adminHandler := adminhandler.NewHandler(clusterJobs, log)
 
-	importSvc := importservice.NewService(docRepo, config.GetImportConfig(), log)
+	importSvc := importservice.NewService(docRepo, locker, config.GetImportConfig(), log)
 	importWorkerDone := importservice.StartWorker(ctx, importSvc)
-	clusterWorkerDone := clusterservice.StartWorker(ctx, clusterSvc)
+	var clusterWake <-chan struct{}
+	if clusterCfg.Listen {
+		clusterWake = db.Listen(ctx, pool, db.DocumentsInsertedChannel, log)
+	}
+	clusterWorkerDone := clusterservice.StartWorker(ctx, clusterSvc, clusterWake)
 
 	r := chi.NewRouter()
 	metrics.NewRegistry()
@@ -57,11 +69,31 @@ func Start(cfg config.Config, log logger.Logger) error {
 	r.Route("/documents", func(r chi.Router) {
 		r.Post("/", docHandler.Create)
 		r.Get("/{id}", docHandler.GetByID)
+		r.Get("/{id}/duplicates", docHandler.Duplicates)
 	})
 
 	r.Route("/clusters", func(r chi.Router) {
 		r.Get("/", clusterHandler.List)
+		r.Get("/tree", clusterHandler.Tree)
+		r.Get("/{id}", clusterHandler.Get)
+		r.Patch("/{id}", clusterHandler.Patch)
+		r.Post("/{id}/merge", clusterHandler.Merge)
+		r.Post("/{id}/split", clusterHandler.Split)
+		r.Get("/{id}/children", clusterHandler.Children)
 		r.Get("/{id}/documents", docHandler.ListByCluster)
+		r.Get("/{id}/representatives", docHandler.Representatives)
+	})
+	r.Get("/map", docHandler.Map)
+	r.Route("/cluster-runs", func(r chi.Router) {
+		r.Get("/", clusterHandler.ListRuns)
+		r.Get("/compare", clusterHandler.Compare)
+		r.Get("/{id}", clusterHandler.GetRun)
+		r.Post("/{id}/rollback", clusterHandler.Rollback)
+	})
+	r.Route("/admin/cluster-jobs", func(r chi.Router) {
+		r.Post("/", adminHandler.StartClusterJob)
+		r.Get("/{id}", adminHandler.GetClusterJob)
+		r.Delete("/{id}", adminHandler.CancelClusterJob)
 	})
 	r.Handle("/metrics", metrics.Handler())
 
@@ -91,6 +123,7 @@ func Start(cfg config.Config, log logger.Logger) error {
 		if clusterWorkerDone != nil {
 			<-clusterWorkerDone
 		}
+		clusterJobs.Wait()
 		close(workersStopped)
 	}()
 
@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"sync/atomic"
+
+	"NeoBIT/internal/vecmath"
+)
+
+// centroidSet answers nearest-centroid queries without allocating: centroid
+// norms are computed once, L2 compares squared distances and takes the root
+// of the winner only.
+type centroidSet struct {
+	metric    Metric
+	centroids [][]float32
+	norms     []float32
+}
+
+func newCentroidSet(centroids [][]float32, metric Metric) centroidSet {
+	s := centroidSet{metric: metric, centroids: centroids}
+	if metric == MetricCosine {
+		s.norms = vecmath.Norms(centroids)
+	}
+	return s
+}
+
+// nearest returns the index of the centroid closest to p and its distance
+// under the set's metric.
+func (s centroidSet) nearest(p []float32) (int, float32) {
+	best := 0
+	switch s.metric {
+	case MetricCosine:
+		np := vecmath.Norm(p)
+		bestDist := vecmath.CosineDistance(p, s.centroids[0], np, s.norms[0])
+		for c := 1; c < len(s.centroids); c++ {
+			if d := vecmath.CosineDistance(p, s.centroids[c], np, s.norms[c]); d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+		return best, bestDist
+	case MetricInnerProduct:
+		bestDot := vecmath.Dot(p, s.centroids[0])
+		for c := 1; c < len(s.centroids); c++ {
+			if d := vecmath.Dot(p, s.centroids[c]); d > bestDot {
+				best, bestDot = c, d
+			}
+		}
+		return best, -bestDot
+	default:
+		bestDist := vecmath.SquaredL2(p, s.centroids[0])
+		for c := 1; c < len(s.centroids); c++ {
+			if d := vecmath.SquaredL2(p, s.centroids[c]); d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+		return best, vecmath.Sqrt(bestDist)
+	}
+}
+
+// assign points every point at its nearest centroid, sharded across CPUs,
+// and returns how many assignments changed. dists may be nil.
+func (s centroidSet) assign(points [][]float32, assignments []int, dists []float32) int {
+	var changed atomic.Int64
+	vecmath.ParallelFor(len(points), func(lo, hi int) {
+		n := 0
+		for i := lo; i < hi; i++ {
+			c, d := s.nearest(points[i])
+			if c != assignments[i] {
+				assignments[i] = c
+				n++
+			}
+			if dists != nil {
+				dists[i] = d
+			}
+		}
+		changed.Add(int64(n))
+	})
+	return int(changed.Load())
+}
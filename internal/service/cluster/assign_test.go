@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+)
+
+func randomPoints(n, dim int, seed int64) [][]float32 {
+	rnd := newRand(seed)
+	points := make([][]float32, n)
+	for i := range points {
+		p := make([]float32, dim)
+		for j := range p {
+			p[j] = float32(rnd.NormFloat64())
+		}
+		points[i] = p
+	}
+	return points
+}
+
+// naiveAssign is the serial brute-force baseline centroidSet is checked and
+// benchmarked against.
+func naiveAssign(points, centroids [][]float32, metric Metric, assignments []int, dists []float32) {
+	for i, p := range points {
+		best, bestDist := 0, metric.Distance(p, centroids[0])
+		for c := 1; c < len(centroids); c++ {
+			if d := metric.Distance(p, centroids[c]); d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+		assignments[i], dists[i] = best, bestDist
+	}
+}
+
+func TestCentroidSetMatchesBruteForce(t *testing.T) {
+	points := randomPoints(3000, 37, 11)
+	centroids := randomPoints(12, 37, 12)
+	for _, metric := range []Metric{MetricCosine, MetricL2, MetricInnerProduct} {
+		want := make([]int, len(points))
+		wantDists := make([]float32, len(points))
+		naiveAssign(points, centroids, metric, want, wantDists)
+
+		got := make([]int, len(points))
+		gotDists := make([]float32, len(points))
+		for i := range got {
+			got[i] = -1
+		}
+		if changed := newCentroidSet(centroids, metric).assign(points, got, gotDists); changed != len(points) {
+			t.Fatalf("%s: expected %d changed assignments, got %d", metric, len(points), changed)
+		}
+		for i := range points {
+			if got[i] != want[i] {
+				t.Fatalf("%s: point %d assigned to %d, brute force picks %d", metric, i, got[i], want[i])
+			}
+			if math.Abs(float64(gotDists[i]-wantDists[i])) > 1e-4*math.Max(1, math.Abs(float64(wantDists[i]))) {
+				t.Fatalf("%s: point %d distance %f, brute force %f", metric, i, gotDists[i], wantDists[i])
+			}
+		}
+		if changed := newCentroidSet(centroids, metric).assign(points, got, nil); changed != 0 {
+			t.Fatalf("%s: expected a repeated assignment to change nothing, got %d", metric, changed)
+		}
+	}
+}
+
+func BenchmarkAssign100k(b *testing.B) {
+	points := randomPoints(100_000, 384, 1)
+	centroids := randomPoints(64, 384, 2)
+	assignments := make([]int, len(points))
+	dists := make([]float32, len(points))
+
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveAssign(points, centroids, MetricL2, assignments, dists)
+		}
+		b.ReportMetric(float64(len(points)*b.N)/b.Elapsed().Seconds(), "points/s")
+	})
+	for _, metric := range []Metric{MetricCosine, MetricL2, MetricInnerProduct} {
+		b.Run(string(metric), func(b *testing.B) {
+			b.ReportAllocs()
+			set := newCentroidSet(centroids, metric)
+			for i := 0; i < b.N; i++ {
+				set.assign(points, assignments, dists)
+			}
+			b.ReportMetric(float64(len(points)*b.N)/b.Elapsed().Seconds(), "points/s")
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+)
+
+type KScore struct {
+	K          int     `json:"k"`
+	Inertia    float64 `json:"inertia"`
+	Silhouette float64 `json:"silhouette"`
+}
+
+// selectK fits the configured algorithm for every K in [minK, maxK] on a
+// sample of points and returns the K with the best mean silhouette.
+func selectK(ctx context.Context, factory Factory, opts Options, points [][]float32) (int, []KScore, error) {
+	cfg := opts.Config
+	sample := samplePoints(points, cfg.AutoKSample, opts)
+
+	minK := cfg.AutoKMin
+	if minK < 2 {
+		minK = 2
+	}
+	maxK := cfg.AutoKMax
+	if maxK > len(sample)-1 {
+		maxK = len(sample) - 1
+	}
+	if maxK < minK {
+		return clampK(minK, len(points)), nil, nil
+	}
+
+	dists := pairwiseDistances(sample, opts.Metric)
+	dist := func(i, j int) float64 { return float64(dists[i][j]) }
+
+	scores := make([]KScore, 0, maxK-minK+1)
+	best := -1
+	for k := minK; k <= maxK; k++ {
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+		trial := opts
+		trial.K = k
+		res, err := factory(trial).Fit(ctx, sample)
+		if err != nil {
+			return 0, nil, fmt.Errorf("auto k: fit k=%d: %w", k, err)
+		}
+		scores = append(scores, KScore{
+			K:          k,
+			Inertia:    res.Diagnostics.Inertia,
+			Silhouette: silhouetteWith(res.Assignments, dist),
+		})
+		if best < 0 || scores[len(scores)-1].Silhouette > scores[best].Silhouette {
+			best = len(scores) - 1
+		}
+	}
+	return scores[best].K, scores, nil
+}
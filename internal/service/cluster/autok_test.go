@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"NeoBIT/internal/config"
+)
+
+func TestSelectKFindsBlobCount(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.AutoKMin = 2
+	cfg.AutoKMax = 6
+	cfg.AutoKSample = 0
+	factory, err := Lookup("kmeans")
+	if err != nil {
+		t.Fatalf("unexpected lookup error: %v", err)
+	}
+
+	k, scores, err := selectK(context.Background(), factory, Options{
+		Config: cfg,
+		Metric: MetricL2,
+		Rand:   newRand(13),
+	}, blobs(30))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if k != 3 {
+		t.Fatalf("expected k=3, got %d (scores %v)", k, scores)
+	}
+	if len(scores) != 5 {
+		t.Fatalf("expected 5 scored candidates, got %d", len(scores))
+	}
+}
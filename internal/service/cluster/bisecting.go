@@ -0,0 +1,178 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/vecmath"
+)
+
+// bisectingClusterer builds a cluster tree top-down: it repeatedly splits the
+// leaf with the largest within-cluster error with 2-means until there are K
+// leaves. Centroids holds every tree node; Assignments point at leaves.
+type bisectingClusterer struct {
+	centroidModel
+	opts   Options
+	leaves []int
+}
+
+func newBisectingClusterer(opts Options) Clusterer {
+	return &bisectingClusterer{centroidModel: centroidModel{metric: opts.Metric}, opts: opts}
+}
+
+const noParent = -1
+
+type bisectNode struct {
+	parent   int
+	centroid []float32
+	members  []int
+	sse      float64
+}
+
+func (c *bisectingClusterer) Fit(ctx context.Context, points [][]float32) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, fmt.Errorf("bisecting kmeans: no points")
+	}
+	k := clampK(c.opts.K, len(points))
+
+	all := make([]int, len(points))
+	for i := range all {
+		all[i] = i
+	}
+	nodes := []bisectNode{}
+	leaves := []int{}
+	root := bisectNode{parent: noParent, members: all}
+	if k == 1 {
+		nodes = append(nodes, wholeNode(points, all, c.opts.Metric))
+		leaves = append(leaves, 0)
+	}
+
+	iterations := 0
+	for len(leaves) < k {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		target := root
+		targetIdx := -1
+		if len(nodes) > 0 {
+			targetIdx = worstLeaf(nodes, leaves)
+			if targetIdx < 0 {
+				break
+			}
+			target = nodes[targetIdx]
+		}
+
+		subset := make([][]float32, len(target.members))
+		for i, m := range target.members {
+			subset[i] = points[m]
+		}
+		init := initCentroids(subset, 2, c.opts.Config.Init, c.opts.Rand)
+		res := kmeans(subset, init, c.opts.Metric, c.opts.Config.MaxIterations, c.opts.Config.Tolerance)
+		iterations += res.iterations
+
+		halves := [2][]int{}
+		for i, a := range res.assignments {
+			halves[a] = append(halves[a], target.members[i])
+		}
+		if len(halves[0]) == 0 || len(halves[1]) == 0 {
+			if targetIdx < 0 {
+				nodes = append(nodes, wholeNode(points, all, c.opts.Metric))
+				leaves = append(leaves, 0)
+				break
+			}
+			nodes[targetIdx].sse = 0
+			continue
+		}
+
+		if targetIdx >= 0 {
+			leaves = removeLeaf(leaves, targetIdx)
+		}
+		for h, members := range halves {
+			nodes = append(nodes, bisectNode{
+				parent:   targetIdx,
+				centroid: res.centroids[h],
+				members:  members,
+				sse:      nodeSSE(points, members, res.centroids[h]),
+			})
+			leaves = append(leaves, len(nodes)-1)
+		}
+	}
+
+	centroids := make([][]float32, len(nodes))
+	parents := make([]int, len(nodes))
+	for i, n := range nodes {
+		centroids[i] = n.centroid
+		parents[i] = n.parent
+	}
+	assignments := make([]int, len(points))
+	for _, leaf := range leaves {
+		for _, m := range nodes[leaf].members {
+			assignments[m] = leaf
+		}
+	}
+
+	c.centroids = make([][]float32, len(leaves))
+	c.leaves = leaves
+	for i, leaf := range leaves {
+		c.centroids[i] = centroids[leaf]
+	}
+
+	return Result{
+		Assignments: assignments,
+		Centroids:   centroids,
+		Parents:     parents,
+		Diagnostics: Diagnostics{
+			Iterations: iterations,
+			Converged:  true,
+			Inertia:    inertia(points, assignments, centroids),
+		},
+	}, nil
+}
+
+func (c *bisectingClusterer) Predict(points [][]float32) ([]int, error) {
+	assignments, err := c.centroidModel.Predict(points)
+	if err != nil {
+		return nil, err
+	}
+	for i, a := range assignments {
+		assignments[i] = c.leaves[a]
+	}
+	return assignments, nil
+}
+
+func wholeNode(points [][]float32, all []int, metric Metric) bisectNode {
+	centroid := metric.project(memberMean(points, make([]int, len(points)), 0))
+	return bisectNode{parent: noParent, centroid: centroid, members: all}
+}
+
+func worstLeaf(nodes []bisectNode, leaves []int) int {
+	worst := -1
+	for _, leaf := range leaves {
+		if len(nodes[leaf].members) < 2 || nodes[leaf].sse <= 0 {
+			continue
+		}
+		if worst < 0 || nodes[leaf].sse > nodes[worst].sse {
+			worst = leaf
+		}
+	}
+	return worst
+}
+
+func removeLeaf(leaves []int, leaf int) []int {
+	out := leaves[:0]
+	for _, l := range leaves {
+		if l != leaf {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func nodeSSE(points [][]float32, members []int, centroid []float32) float64 {
+	var sum float64
+	for _, m := range members {
+		sum += float64(vecmath.SquaredL2(points[m], centroid))
+	}
+	return sum
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"NeoBIT/internal/config"
+)
+
+func TestBisectingKMeansBuildsTree(t *testing.T) {
+	points := [][]float32{
+		{0, 0}, {0, 1},
+		{3, 0}, {3, 1},
+		{100, 100}, {100, 101},
+	}
+	c := newBisectingClusterer(Options{
+		Config: config.DefaultClusterConfig(),
+		Metric: MetricL2,
+		K:      3,
+		Rand:   newRand(9),
+	})
+	res, err := c.Fit(context.Background(), points)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Centroids) != 4 || len(res.Parents) != 4 {
+		t.Fatalf("expected 2 top-level nodes and 2 children, got %d nodes", len(res.Centroids))
+	}
+	if res.leafCount() != 3 {
+		t.Fatalf("expected 3 leaves, got %d", res.leafCount())
+	}
+
+	top := 0
+	for _, p := range res.Parents {
+		if p == noParent {
+			top++
+		}
+	}
+	if top != 2 {
+		t.Fatalf("expected 2 top-level clusters, got %d", top)
+	}
+
+	left := res.Assignments[0]
+	if res.Assignments[1] != left || res.Assignments[2] == left || res.Assignments[3] != res.Assignments[2] {
+		t.Fatalf("expected the near blobs split into sibling leaves, got %v", res.Assignments)
+	}
+	if res.Parents[left] != res.Parents[res.Assignments[2]] || res.Parents[left] == noParent {
+		t.Fatalf("expected near blobs to share a parent, got parents %v", res.Parents)
+	}
+
+	got, err := c.Predict([][]float32{{99, 99}})
+	if err != nil {
+		t.Fatalf("unexpected predict error: %v", err)
+	}
+	if got[0] != res.Assignments[4] {
+		t.Fatalf("expected prediction in far leaf, got %d", got[0])
+	}
+}
@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"NeoBIT/internal/config"
+)
+
+type Diagnostics struct {
+	Iterations        int      `json:"iterations"`
+	Converged         bool     `json:"converged"`
+	Inertia           float64  `json:"inertia"`
+	Noise             int      `json:"noise"`
+	KScores           []KScore `json:"k_scores,omitempty"`
+	PCADims           int      `json:"pca_dims,omitempty"`
+	ExplainedVariance float64  `json:"explained_variance,omitempty"`
+}
+
+// Result describes a fitted clustering. Parents is set by hierarchical
+// clusterers: Parents[i] is the index of the parent centroid of centroid i,
+// or -1 for a top-level cluster. Assignments then point at leaf centroids.
+// projection is set when the points were reduced with PCA before fitting.
+type Result struct {
+	Assignments []int
+	Centroids   [][]float32
+	Parents     []int
+	Diagnostics Diagnostics
+	projection  *projection
+}
+
+// Clusterer fits a model over a set of points. Predict assigns new points
+// using the model learned by the last successful Fit.
+type Clusterer interface {
+	Fit(ctx context.Context, points [][]float32) (Result, error)
+	Predict(points [][]float32) ([]int, error)
+}
+
+type Options struct {
+	Config      config.ClusterConfig
+	Metric      Metric
+	K           int
+	Rand        *rand.Rand
+	Sample      func(ctx context.Context, n int) ([][]float32, error)
+	RegionQuery func(ctx context.Context, point []float32, eps float64, limit int) (int, error)
+}
+
+// noiseLabeler is implemented by clusterers that may leave points outside of
+// every cluster by labelling them with NoiseLabel.
+type noiseLabeler interface {
+	LabelsNoise() bool
+}
+
+type Factory func(opts Options) Clusterer
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"simple":           newOnePassClusterer,
+		"kmeans":           newKMeansClusterer,
+		"minibatch_kmeans": newMiniBatchClusterer,
+		"dbscan":           newDBSCANClusterer,
+		"bisecting_kmeans": newBisectingClusterer,
+	}
+)
+
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+func Lookup(name string) (Factory, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("cluster: unsupported algorithm %q (available: %v)", name, algorithmsLocked())
+	}
+	return factory, nil
+}
+
+func Algorithms() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return algorithmsLocked()
+}
+
+func algorithmsLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type centroidModel struct {
+	metric    Metric
+	centroids [][]float32
+}
+
+func (m *centroidModel) Predict(points [][]float32) ([]int, error) {
+	if len(m.centroids) == 0 {
+		return nil, fmt.Errorf("cluster: model is not fitted")
+	}
+	assignments := make([]int, len(points))
+	newCentroidSet(m.centroids, m.metric).assign(points, assignments, nil)
+	return assignments, nil
+}
+
+func clampK(k, n int) int {
+	if k <= 0 {
+		k = 1
+	}
+	if k > n {
+		k = n
+	}
+	return k
+}
+
+func (r Result) leafCount() int {
+	if r.Parents == nil {
+		return len(r.Centroids)
+	}
+	internal := make(map[int]struct{}, len(r.Parents))
+	for _, p := range r.Parents {
+		if p >= 0 {
+			internal[p] = struct{}{}
+		}
+	}
+	return len(r.Centroids) - len(internal)
+}
@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+)
+
+func TestNewServiceRejectsUnknownAlgorithm(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.Algorithm = "does-not-exist"
+	if _, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, cfg, logger.Nop()); err == nil {
+		t.Fatalf("expected error for unknown algorithm")
+	}
+}
+
+func TestNewServiceRejectsUnknownMetric(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "hamming"
+	if _, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, cfg, logger.Nop()); err == nil {
+		t.Fatalf("expected error for unknown metric")
+	}
+}
+
+func TestNewServiceRejectsNegativePCADims(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.PCADims = -1
+	if _, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, cfg, logger.Nop()); err == nil {
+		t.Fatalf("expected error for negative pca dims")
+	}
+}
+
+func TestNewServiceRejectsPCAVarianceOutsideUnitInterval(t *testing.T) {
+	for _, v := range []float64{-0.5, 1, 1.5} {
+		cfg := config.DefaultClusterConfig()
+		cfg.PCAVariance = v
+		if _, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, cfg, logger.Nop()); err == nil {
+			t.Fatalf("expected error for pca variance %v", v)
+		}
+	}
+	cfg := config.DefaultClusterConfig()
+	cfg.PCAVariance = 0.9
+	if _, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, cfg, logger.Nop()); err != nil {
+		t.Fatalf("unexpected error for pca variance 0.9: %v", err)
+	}
+}
+
+func TestRegisteredClusterersFitAndPredict(t *testing.T) {
+	points := [][]float32{{0, 0}, {0, 1}, {10, 10}, {10, 11}}
+	sample := func(ctx context.Context, n int) ([][]float32, error) {
+		return points, nil
+	}
+	for _, name := range []string{"simple", "kmeans", "minibatch_kmeans"} {
+		factory, err := Lookup(name)
+		if err != nil {
+			t.Fatalf("%s: unexpected lookup error: %v", name, err)
+		}
+		c := factory(Options{
+			Config: config.DefaultClusterConfig(),
+			Metric: MetricL2,
+			K:      2,
+			Rand:   newRand(11),
+			Sample: sample,
+		})
+		if _, err := c.Predict(points); err == nil {
+			t.Fatalf("%s: expected predict before fit to fail", name)
+		}
+		res, err := c.Fit(context.Background(), points)
+		if err != nil {
+			t.Fatalf("%s: unexpected fit error: %v", name, err)
+		}
+		if len(res.Assignments) != len(points) || len(res.Centroids) != 2 {
+			t.Fatalf("%s: unexpected result shape", name)
+		}
+		got, err := c.Predict([][]float32{{9, 9}})
+		if err != nil {
+			t.Fatalf("%s: unexpected predict error: %v", name, err)
+		}
+		if got[0] != res.Assignments[2] {
+			t.Fatalf("%s: expected predicted point in the far cluster", name)
+		}
+	}
+}
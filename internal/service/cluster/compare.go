@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"NeoBIT/internal/models/cluster"
+)
+
+var (
+	ErrInvalidComparison = errors.New("invalid clustering comparison")
+	ErrNoCommonDocuments = errors.New("clusterings share no documents")
+	ErrClusteringGone    = errors.New("clustering no longer exists")
+)
+
+// Compare measures the agreement between the clusterings left by runs (or,
+// with by set to generation, generations) a and b: Adjusted Rand Index,
+// Normalized Mutual Information and the cluster-to-cluster overlap over the
+// documents both assigned. Noise counts as a cluster of its own. Runs whose
+// assignments were dropped by a rollback or a pruned generation cannot be
+// compared and yield ErrClusteringGone.
+func (s *ClusterService) Compare(ctx context.Context, by string, a, b int64) (cluster.Comparison, error) {
+	if s.clusterRepo == nil {
+		return cluster.Comparison{}, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	if by == "" {
+		by = cluster.CompareByRun
+	}
+	if by != cluster.CompareByRun && by != cluster.CompareByGeneration {
+		return cluster.Comparison{}, fmt.Errorf("%w: by must be %s or %s", ErrInvalidComparison, cluster.CompareByRun, cluster.CompareByGeneration)
+	}
+	for _, id := range []int64{a, b} {
+		exists, err := s.clusterRepo.ClusteringExists(ctx, by, id)
+		if err != nil {
+			return cluster.Comparison{}, fmt.Errorf("cluster service: %w", err)
+		}
+		if !exists {
+			return cluster.Comparison{}, fmt.Errorf("%w: assignments of %s %d were rolled back or pruned", ErrClusteringGone, by, id)
+		}
+	}
+	cells, err := s.clusterRepo.Overlap(ctx, by, a, b)
+	if err != nil {
+		return cluster.Comparison{}, fmt.Errorf("cluster service: %w", err)
+	}
+	cmp := compareOverlap(cells)
+	if cmp.Docs == 0 {
+		return cluster.Comparison{}, fmt.Errorf("%w: %s %d and %s %d", ErrNoCommonDocuments, by, a, by, b)
+	}
+	cmp.By, cmp.A, cmp.B = by, a, b
+	return cmp, nil
+}
+
+// CompareAssignments compares two labellings of the same points, such as the
+// Assignments of two Results. NoiseLabel counts as a cluster of its own.
+func CompareAssignments(a, b []int) cluster.Comparison {
+	type pair struct{ a, b int }
+	counts := make(map[pair]int64)
+	for i := range min(len(a), len(b)) {
+		counts[pair{a[i], b[i]}]++
+	}
+	label := func(l int) *int64 {
+		if l == NoiseLabel {
+			return nil
+		}
+		id := int64(l)
+		return &id
+	}
+	cells := make([]cluster.OverlapCell, 0, len(counts))
+	for p, n := range counts {
+		cells = append(cells, cluster.OverlapCell{A: label(p.a), B: label(p.b), Docs: n})
+	}
+	return compareOverlap(cells)
+}
+
+// compareOverlap computes ARI and NMI (arithmetic mean normalisation) from a
+// contingency table and lays it out as a matrix with sorted rows and columns.
+func compareOverlap(cells []cluster.OverlapCell) cluster.Comparison {
+	rows := overlapAxis(cells, func(c cluster.OverlapCell) *int64 { return c.A })
+	cols := overlapAxis(cells, func(c cluster.OverlapCell) *int64 { return c.B })
+	cmp := cluster.Comparison{
+		ClustersA: rows.ids,
+		ClustersB: cols.ids,
+		Overlap:   make([][]int64, len(rows.ids)),
+	}
+	for i := range cmp.Overlap {
+		cmp.Overlap[i] = make([]int64, len(cols.ids))
+	}
+	rowSums := make([]float64, len(rows.ids))
+	colSums := make([]float64, len(cols.ids))
+	var pairs float64
+	for _, c := range cells {
+		i, j := rows.index(c.A), cols.index(c.B)
+		cmp.Overlap[i][j] += c.Docs
+		rowSums[i] += float64(c.Docs)
+		colSums[j] += float64(c.Docs)
+		cmp.Docs += c.Docs
+		pairs += choose2(float64(c.Docs))
+	}
+	if cmp.Docs == 0 {
+		return cmp
+	}
+	n := float64(cmp.Docs)
+
+	var rowPairs, colPairs, entropyA, entropyB float64
+	for _, a := range rowSums {
+		rowPairs += choose2(a)
+		entropyA -= a / n * math.Log(a/n)
+	}
+	for _, b := range colSums {
+		colPairs += choose2(b)
+		entropyB -= b / n * math.Log(b/n)
+	}
+
+	expected := rowPairs * colPairs / choose2(n)
+	if maxIndex := (rowPairs + colPairs) / 2; maxIndex == expected {
+		cmp.ARI = 1
+	} else {
+		cmp.ARI = (pairs - expected) / (maxIndex - expected)
+	}
+
+	var mutual float64
+	for i, row := range cmp.Overlap {
+		for j, nij := range row {
+			if nij > 0 {
+				mutual += float64(nij) / n * math.Log(n*float64(nij)/(rowSums[i]*colSums[j]))
+			}
+		}
+	}
+	if entropyA == 0 && entropyB == 0 {
+		cmp.NMI = 1
+	} else {
+		cmp.NMI = math.Max(0, mutual/((entropyA+entropyB)/2))
+	}
+	return cmp
+}
+
+func choose2(n float64) float64 {
+	return n * (n - 1) / 2
+}
+
+// axis maps the cluster ids of one side of a comparison to matrix indexes;
+// noise, if present, comes first.
+type axis struct {
+	ids   []*int64
+	noise int
+	pos   map[int64]int
+}
+
+func overlapAxis(cells []cluster.OverlapCell, side func(cluster.OverlapCell) *int64) axis {
+	seen := make(map[int64]struct{})
+	hasNoise := false
+	for _, c := range cells {
+		if id := side(c); id == nil {
+			hasNoise = true
+		} else {
+			seen[*id] = struct{}{}
+		}
+	}
+	sorted := make([]int64, 0, len(seen))
+	for id := range seen {
+		sorted = append(sorted, id)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	ax := axis{noise: -1, pos: make(map[int64]int, len(sorted))}
+	if hasNoise {
+		ax.noise = 0
+		ax.ids = append(ax.ids, nil)
+	}
+	for _, id := range sorted {
+		ax.pos[id] = len(ax.ids)
+		ax.ids = append(ax.ids, &id)
+	}
+	return ax
+}
+
+func (a axis) index(id *int64) int {
+	if id == nil {
+		return a.noise
+	}
+	return a.pos[*id]
+}
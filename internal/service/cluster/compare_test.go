@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+func TestCompareAssignments(t *testing.T) {
+	cases := []struct {
+		a, b     []int
+		ari, nmi float64
+	}{
+		{[]int{0, 0, 1, 1}, []int{1, 1, 0, 0}, 1, 1},
+		{[]int{0, 0, 1, 1}, []int{0, 0, 1, 2}, 4.0 / 7, 0.8},
+		{[]int{0, 0, 0, 0}, []int{0, 0, 0, 0}, 1, 1},
+		{[]int{0, 0, 1, 1}, []int{0, 1, 0, 1}, -0.5, 0},
+	}
+	for _, tc := range cases {
+		got := CompareAssignments(tc.a, tc.b)
+		if math.Abs(got.ARI-tc.ari) > 1e-9 || math.Abs(got.NMI-tc.nmi) > 1e-9 {
+			t.Fatalf("%v vs %v: expected ARI %f NMI %f, got %f %f", tc.a, tc.b, tc.ari, tc.nmi, got.ARI, got.NMI)
+		}
+		if got.Docs != int64(len(tc.a)) {
+			t.Fatalf("%v vs %v: expected %d docs, got %d", tc.a, tc.b, len(tc.a), got.Docs)
+		}
+	}
+}
+
+func TestCompareAssignmentsOverlapMatrix(t *testing.T) {
+	got := CompareAssignments([]int{NoiseLabel, 0, 0, 1}, []int{2, 2, 5, 5})
+	if len(got.ClustersA) != 3 || got.ClustersA[0] != nil || *got.ClustersA[1] != 0 || *got.ClustersA[2] != 1 {
+		t.Fatalf("expected rows noise, 0, 1, got %v", got.ClustersA)
+	}
+	if len(got.ClustersB) != 2 || *got.ClustersB[0] != 2 || *got.ClustersB[1] != 5 {
+		t.Fatalf("expected columns 2, 5, got %v", got.ClustersB)
+	}
+	want := [][]int64{{1, 0}, {1, 1}, {0, 1}}
+	if !reflect.DeepEqual(got.Overlap, want) {
+		t.Fatalf("expected overlap %v, got %v", want, got.Overlap)
+	}
+}
+
+func TestKMeansIsStableAcrossSeeds(t *testing.T) {
+	points := blobs(50)
+	var labels [][]int
+	for _, seed := range []int64{1, 2} {
+		centroids := initCentroids(points, 3, initKMeansPlusPlus, newRand(seed))
+		labels = append(labels, kmeans(points, centroids, MetricL2, 20, 1e-6).assignments)
+	}
+	if got := CompareAssignments(labels[0], labels[1]); got.ARI != 1 || math.Abs(got.NMI-1) > 1e-9 {
+		t.Fatalf("expected identical clusterings of well separated blobs, got ARI %f NMI %f", got.ARI, got.NMI)
+	}
+}
+
+type overlapRepo struct {
+	fakeClusterRepo
+	by    string
+	cells []cluster.OverlapCell
+	gone  map[int64]bool
+}
+
+func (o *overlapRepo) ClusteringExists(ctx context.Context, by string, id int64) (bool, error) {
+	return !o.gone[id], nil
+}
+
+func (o *overlapRepo) Overlap(ctx context.Context, by string, a, b int64) ([]cluster.OverlapCell, error) {
+	o.by = by
+	return o.cells, nil
+}
+
+func TestCompare(t *testing.T) {
+	one, two := int64(1), int64(2)
+	repo := &overlapRepo{cells: []cluster.OverlapCell{{A: &one, B: &two, Docs: 3}, {A: &two, B: &one, Docs: 4}}}
+	svc, err := NewService(repo, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := svc.Compare(context.Background(), "", 5, 9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.by != cluster.CompareByRun || got.A != 5 || got.B != 9 || got.Docs != 7 || got.ARI != 1 {
+		t.Fatalf("expected runs 5 and 9 to agree on 7 docs, got %+v", got)
+	}
+
+	if _, err := svc.Compare(context.Background(), "cluster", 5, 9); !errors.Is(err, ErrInvalidComparison) {
+		t.Fatalf("expected ErrInvalidComparison, got %v", err)
+	}
+	repo.cells = nil
+	if _, err := svc.Compare(context.Background(), cluster.CompareByGeneration, 1, 2); !errors.Is(err, ErrNoCommonDocuments) {
+		t.Fatalf("expected ErrNoCommonDocuments, got %v", err)
+	}
+}
+
+func TestCompareRejectsRolledBackRun(t *testing.T) {
+	// Run 9 spawned the clusters its documents went to; rolling back to run 5
+	// dropped them and, with them, the run's assignments.
+	one := int64(1)
+	repo := &overlapRepo{
+		cells: []cluster.OverlapCell{{A: &one, B: &one, Docs: 3}},
+		gone:  map[int64]bool{9: true},
+	}
+	svc, err := NewService(repo, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := svc.Compare(context.Background(), cluster.CompareByRun, 5, 9); !errors.Is(err, ErrClusteringGone) {
+		t.Fatalf("expected ErrClusteringGone, got %v", err)
+	}
+	if repo.by != "" {
+		t.Fatalf("expected no overlap to be computed, got by=%q", repo.by)
+	}
+	if _, err := svc.Compare(context.Background(), cluster.CompareByRun, 5, 7); err != nil {
+		t.Fatalf("expected runs that survived the rollback to compare, got %v", err)
+	}
+}
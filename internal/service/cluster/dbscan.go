@@ -0,0 +1,210 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/vecmath"
+)
+
+const NoiseLabel = -1
+
+// dbscanClusterer runs DBSCAN over the batch. When Options.RegionQuery is
+// set, core points are decided by the density of the whole corpus (via the
+// HNSW index) rather than of the batch alone, so a small random batch of a
+// large table still finds dense regions; expansion stays within the batch.
+//
+// Building neighbourhoods is quadratic in time and memory, so at most
+// Config.DBSCANSample points are expanded; the rest of the batch is labelled
+// like Predict does, by the nearest core point within eps.
+type dbscanClusterer struct {
+	opts  Options
+	cores [][]float32
+	label []int
+}
+
+func newDBSCANClusterer(opts Options) Clusterer {
+	return &dbscanClusterer{opts: opts}
+}
+
+func (c *dbscanClusterer) LabelsNoise() bool {
+	return true
+}
+
+func (c *dbscanClusterer) Fit(ctx context.Context, points [][]float32) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, fmt.Errorf("dbscan: no points")
+	}
+	eps := c.opts.Config.Eps
+	minPts := c.opts.Config.MinPts
+	if eps <= 0 {
+		return Result{}, fmt.Errorf("dbscan: eps must be positive")
+	}
+	if minPts <= 0 {
+		minPts = 5
+	}
+
+	expanded := points
+	if limit := c.opts.Config.DBSCANSample; limit > 0 && len(points) > limit {
+		expanded = samplePoints(points, limit, c.opts)
+	}
+	assignments, clusters, err := c.expand(ctx, expanded, eps, minPts)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(expanded) < len(points) {
+		assignments = c.nearestCore(points)
+	}
+
+	centroids := make([][]float32, clusters)
+	for label := range centroids {
+		centroids[label] = c.opts.Metric.project(memberMean(points, assignments, label))
+	}
+	noise := 0
+	for _, a := range assignments {
+		if a == NoiseLabel {
+			noise++
+		}
+	}
+
+	return Result{
+		Assignments: assignments,
+		Centroids:   centroids,
+		Diagnostics: Diagnostics{
+			Iterations: 1,
+			Converged:  true,
+			Noise:      noise,
+		},
+	}, nil
+}
+
+// expand labels points by growing clusters from their core points and keeps
+// the core points as the model. It returns the labels and the cluster count.
+func (c *dbscanClusterer) expand(ctx context.Context, points [][]float32, eps float64, minPts int) ([]int, int, error) {
+	neighbors := c.neighborhoods(points, eps)
+
+	core := make([]bool, len(points))
+	for i, p := range points {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		if c.opts.RegionQuery == nil {
+			core[i] = len(neighbors[i]) >= minPts
+			continue
+		}
+		n, err := c.opts.RegionQuery(ctx, p, eps, minPts)
+		if err != nil {
+			return nil, 0, fmt.Errorf("dbscan: region query: %w", err)
+		}
+		core[i] = n >= minPts
+	}
+
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = NoiseLabel
+	}
+	clusters := 0
+	for i := range points {
+		if !core[i] || assignments[i] != NoiseLabel {
+			continue
+		}
+		label := clusters
+		clusters++
+		assignments[i] = label
+		queue := []int{i}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, j := range neighbors[cur] {
+				if assignments[j] != NoiseLabel {
+					continue
+				}
+				assignments[j] = label
+				if core[j] {
+					queue = append(queue, j)
+				}
+			}
+		}
+	}
+
+	c.cores = c.cores[:0]
+	c.label = c.label[:0]
+	for i, p := range points {
+		if core[i] {
+			c.cores = append(c.cores, p)
+			c.label = append(c.label, assignments[i])
+		}
+	}
+	return assignments, clusters, nil
+}
+
+// neighborhoods returns, for every point, the indexes of the points within
+// eps of it, itself included. Rows are built in parallel; L2 compares squared
+// distances so that no square root is taken.
+func (c *dbscanClusterer) neighborhoods(points [][]float32, eps float64) [][]int {
+	dist := pairDistance(points, c.opts.Metric)
+	limit := float32(eps)
+	if c.opts.Metric == MetricL2 {
+		dist = func(i, j int) float32 { return vecmath.SquaredL2(points[i], points[j]) }
+		limit = float32(eps * eps)
+	}
+	neighbors := make([][]int, len(points))
+	vecmath.ParallelFor(len(points), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			for j := range points {
+				if dist(i, j) <= limit {
+					neighbors[i] = append(neighbors[i], j)
+				}
+			}
+		}
+	})
+	return neighbors
+}
+
+// Predict labels each point with the cluster of its nearest core point, or
+// NoiseLabel when that is farther than eps. Points labelled noise this way
+// are not looked at again as the clusters grow; only a full recluster
+// re-evaluates them.
+func (c *dbscanClusterer) Predict(points [][]float32) ([]int, error) {
+	if len(c.cores) == 0 {
+		return nil, fmt.Errorf("cluster: model is not fitted")
+	}
+	return c.nearestCore(points), nil
+}
+
+func (c *dbscanClusterer) nearestCore(points [][]float32) []int {
+	assignments := make([]int, len(points))
+	if len(c.cores) == 0 {
+		for i := range assignments {
+			assignments[i] = NoiseLabel
+		}
+		return assignments
+	}
+	dists := make([]float32, len(points))
+	newCentroidSet(c.cores, c.opts.Metric).assign(points, assignments, dists)
+	for i, idx := range assignments {
+		assignments[i] = NoiseLabel
+		if float64(dists[i]) <= c.opts.Config.Eps {
+			assignments[i] = c.label[idx]
+		}
+	}
+	return assignments
+}
+
+func memberMean(points [][]float32, assignments []int, label int) []float32 {
+	mean := make([]float32, len(points[0]))
+	n := 0
+	for i, p := range points {
+		if assignments[i] != label {
+			continue
+		}
+		n++
+		for j, v := range p {
+			mean[j] += v
+		}
+	}
+	for j := range mean {
+		mean[j] /= float32(n)
+	}
+	return mean
+}
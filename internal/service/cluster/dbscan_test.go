@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"NeoBIT/internal/config"
+)
+
+func TestDBSCANLeavesOutliersAsNoise(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.Eps = 1.5
+	cfg.MinPts = 3
+	c := newDBSCANClusterer(Options{Config: cfg, Metric: MetricL2})
+
+	points := [][]float32{
+		{0, 0}, {0, 1}, {1, 0}, {1, 1},
+		{10, 10}, {10, 11}, {11, 10},
+		{50, 50},
+	}
+	res, err := c.Fit(context.Background(), points)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Centroids) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(res.Centroids))
+	}
+	if res.Assignments[7] != NoiseLabel || res.Diagnostics.Noise != 1 {
+		t.Fatalf("expected isolated point as noise, got %v", res.Assignments)
+	}
+	if res.Assignments[0] == res.Assignments[4] {
+		t.Fatalf("expected blobs in different clusters, got %v", res.Assignments)
+	}
+
+	got, err := c.Predict([][]float32{{0.5, 0.5}, {30, 30}})
+	if err != nil {
+		t.Fatalf("unexpected predict error: %v", err)
+	}
+	if got[0] != res.Assignments[0] || got[1] != NoiseLabel {
+		t.Fatalf("unexpected predictions %v", got)
+	}
+}
+
+func TestDBSCANUsesCorpusDensity(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.Eps = 1.5
+	cfg.MinPts = 10
+	regionQuery := func(ctx context.Context, point []float32, eps float64, limit int) (int, error) {
+		if point[0] < 5 {
+			return limit, nil
+		}
+		return 1, nil
+	}
+	c := newDBSCANClusterer(Options{Config: cfg, Metric: MetricL2, RegionQuery: regionQuery})
+
+	res, err := c.Fit(context.Background(), [][]float32{{0, 0}, {0, 1}, {20, 20}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Assignments[0] != 0 || res.Assignments[1] != 0 || res.Assignments[2] != NoiseLabel {
+		t.Fatalf("expected dense corpus region to form a cluster, got %v", res.Assignments)
+	}
+}
+
+func TestDBSCANLabelsPointsOutsideSample(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	cfg.Eps = 1.5
+	cfg.MinPts = 3
+	cfg.DBSCANSample = 40
+	c := newDBSCANClusterer(Options{Config: cfg, Metric: MetricL2, Rand: newRand(1)})
+
+	var points [][]float32
+	for i := 0; i < 50; i++ {
+		points = append(points, []float32{float32(i%5) * 0.2, float32(i/5) * 0.1})
+		points = append(points, []float32{20 + float32(i%5)*0.2, float32(i/5) * 0.1})
+	}
+	points = append(points, []float32{100, 100})
+
+	res, err := c.Fit(context.Background(), points)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Centroids) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(res.Centroids))
+	}
+	for i := 0; i < 100; i++ {
+		if res.Assignments[i] != res.Assignments[i%2] {
+			t.Fatalf("point %d labelled %d, expected its blob's %d", i, res.Assignments[i], res.Assignments[i%2])
+		}
+	}
+	if res.Assignments[100] != NoiseLabel || res.Diagnostics.Noise != 1 {
+		t.Fatalf("expected isolated point as noise, got %v", res.Assignments)
+	}
+}
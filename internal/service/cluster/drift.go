@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/metrics"
+	"NeoBIT/internal/models/cluster"
+)
+
+const driftHistoryLimit = 20
+
+// Get returns a cluster with its recent centroid drift.
+func (s *ClusterService) Get(ctx context.Context, id int64) (cluster.ClusterDetail, error) {
+	if s.clusterRepo == nil {
+		return cluster.ClusterDetail{}, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	c, err := s.clusterRepo.Get(ctx, id)
+	if err != nil {
+		return cluster.ClusterDetail{}, err
+	}
+	history, err := s.clusterRepo.DriftHistory(ctx, id, driftHistoryLimit)
+	if err != nil {
+		return cluster.ClusterDetail{}, fmt.Errorf("cluster service: drift history: %w", err)
+	}
+	return cluster.ClusterDetail{Cluster: c, DriftHistory: history}, nil
+}
+
+// RecomputeCentroids resets every centroid of the active generation to the
+// mean of its current documents and records how far it moved. Incremental
+// assignment only nudges centroids with a running mean, so they lag behind
+// re-assignments and rollbacks until this runs.
+func (s *ClusterService) RecomputeCentroids(ctx context.Context) (int, error) {
+	if s.clusterRepo == nil {
+		return 0, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	unlock, err := s.lockWrites(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: lock centroids: %w", err)
+	}
+	defer unlock()
+
+	stats, err := s.clusterRepo.CentroidStats(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	updates := s.centroidUpdates(stats)
+	if err := s.clusterRepo.UpdateCentroids(ctx, updates); err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	if pruned, err := s.clusterRepo.PruneDriftHistory(ctx, s.cfg.DriftHistoryKeep); err != nil {
+		s.log.Warn(ctx, "cluster worker: prune drift history failed", logger.FieldAny("error", err))
+	} else if pruned > 0 {
+		s.log.Info(ctx, "cluster worker: pruned drift history", logger.FieldAny("points", pruned))
+	}
+
+	var maxDrift float64
+	metrics.ResetClusterDrift()
+	for _, u := range updates {
+		metrics.SetClusterDrift(u.ClusterID, u.Drift)
+		maxDrift = max(maxDrift, u.Drift)
+	}
+	s.job.advance(len(updates))
+	s.log.Info(ctx, "cluster worker: centroids recomputed",
+		logger.FieldAny("clusters", len(updates)),
+		logger.FieldAny("max_drift", maxDrift),
+	)
+	return len(updates), nil
+}
+
+func (s *ClusterService) centroidUpdates(stats []cluster.CentroidStat) []cluster.CentroidUpdate {
+	// Inner product "distance" is not a displacement, so drift is measured in
+	// the geometry centroids live in: angular for cosine, euclidean otherwise.
+	driftMetric := MetricL2
+	if s.metric.spherical() {
+		driftMetric = MetricCosine
+	}
+	updates := make([]cluster.CentroidUpdate, 0, len(stats))
+	for _, stat := range stats {
+		centroid := s.metric.project(stat.Mean)
+		updates = append(updates, cluster.CentroidUpdate{
+			ClusterID: stat.ClusterID,
+			Centroid:  centroid,
+			Drift:     float64(driftMetric.Distance(stat.Centroid, centroid)),
+			Size:      stat.Size,
+		})
+	}
+	return updates
+}
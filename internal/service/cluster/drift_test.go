@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+type driftRepo struct {
+	fakeClusterRepo
+	stats   []cluster.CentroidStat
+	updates []cluster.CentroidUpdate
+	pruned  []int
+}
+
+func (d *driftRepo) CentroidStats(ctx context.Context) ([]cluster.CentroidStat, error) {
+	return d.stats, nil
+}
+
+func (d *driftRepo) UpdateCentroids(ctx context.Context, updates []cluster.CentroidUpdate) error {
+	d.updates = updates
+	return nil
+}
+
+func (d *driftRepo) PruneDriftHistory(ctx context.Context, keep int) (int64, error) {
+	d.pruned = append(d.pruned, keep)
+	return 0, nil
+}
+
+func TestRecomputeCentroids(t *testing.T) {
+	repo := &driftRepo{stats: []cluster.CentroidStat{
+		{ClusterID: 1, Centroid: []float32{1, 0}, Mean: []float32{1, 0}, Size: 3},
+		{ClusterID: 2, Centroid: []float32{1, 0}, Mean: []float32{0, 2}, Size: 5},
+	}}
+	svc, err := NewService(repo, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n, err := svc.RecomputeCentroids(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || len(repo.updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d (%d)", n, len(repo.updates))
+	}
+	if got := repo.updates[0]; got.Drift != 0 || got.Size != 3 {
+		t.Fatalf("expected unmoved cluster to have zero drift, got %+v", got)
+	}
+	moved := repo.updates[1]
+	if math.Abs(moved.Drift-1) > 1e-6 {
+		t.Fatalf("expected cosine drift of 1 for an orthogonal move, got %v", moved.Drift)
+	}
+	if moved.Centroid[0] != 0 || math.Abs(float64(moved.Centroid[1])-1) > 1e-6 {
+		t.Fatalf("expected the mean to be projected onto the unit sphere, got %v", moved.Centroid)
+	}
+	if len(repo.pruned) != 1 || repo.pruned[0] != config.DefaultClusterConfig().DriftHistoryKeep {
+		t.Fatalf("expected drift history pruned once to the configured bound, got %v", repo.pruned)
+	}
+}
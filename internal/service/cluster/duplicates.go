@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+)
+
+// DetectDuplicates groups near-duplicate documents, such as reposts of one
+// story under different hn_ids, into duplicate sets. Documents are visited in
+// id order, and each one not yet in a set seeds a new set with those of its
+// DuplicateNeighbors nearest neighbours (found through the HNSW index) that
+// are closer than DuplicateDistance and not in a set either. Matches are not
+// joined transitively: every member is within the threshold of the seed,
+// which names the set, so a chain of slightly different documents cannot
+// collapse into one set. It returns the number of sets.
+func (s *ClusterService) DetectDuplicates(ctx context.Context) (int, error) {
+	if s.docRepo == nil {
+		return 0, fmt.Errorf("cluster service: doc repo is nil")
+	}
+	unlock, ok, err := s.tryLockDuplicates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: lock duplicates: %w", err)
+	}
+	if !ok {
+		s.log.Info(ctx, "cluster worker: duplicate scan running on another instance")
+		return 0, nil
+	}
+	defer unlock()
+
+	maxDistance := s.cfg.DuplicateDistance
+	if maxDistance <= 0 {
+		maxDistance = 0.05
+	}
+	neighbors := s.cfg.DuplicateNeighbors
+	if neighbors <= 0 {
+		neighbors = 10
+	}
+
+	members := make(map[int64]int64)
+	count := 0
+	var afterID int64
+	for {
+		docs, err := s.docRepo.ListEmbeddingsAfter(ctx, afterID, s.batchSize())
+		if err != nil {
+			return 0, fmt.Errorf("cluster service: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+		for _, doc := range docs {
+			if _, ok := members[doc.ID]; ok {
+				continue
+			}
+			dups, err := s.docRepo.NearDuplicates(ctx, doc.ID, doc.Embedding, maxDistance, neighbors)
+			if err != nil {
+				return 0, fmt.Errorf("cluster service: %w", err)
+			}
+			joined := false
+			for _, dup := range dups {
+				if _, ok := members[dup]; ok {
+					continue
+				}
+				members[dup] = doc.ID
+				joined = true
+			}
+			if joined {
+				members[doc.ID] = doc.ID
+				count++
+			}
+		}
+		afterID = docs[len(docs)-1].ID
+		s.job.advance(len(docs))
+	}
+
+	if err := s.docRepo.SaveDuplicateSets(ctx, members); err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	s.log.Info(ctx, "cluster worker: duplicates detected", logger.FieldAny("sets", count), logger.FieldAny("docs", len(members)))
+	return count, nil
+}
+
+// ScoreOutliers refreshes every document's distance to its cluster centroid
+// and its is_outlier flag.
+func (s *ClusterService) ScoreOutliers(ctx context.Context) (int64, error) {
+	if s.clusterRepo == nil {
+		return 0, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	z := s.cfg.OutlierZ
+	if z <= 0 {
+		z = 3
+	}
+	scored, err := s.clusterRepo.ScoreOutliers(ctx, z)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	s.job.advance(int(scored))
+	s.log.Info(ctx, "cluster worker: outliers scored", logger.FieldAny("docs", scored))
+	return scored, nil
+}
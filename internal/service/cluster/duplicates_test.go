@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/document"
+)
+
+type duplicateDocRepo struct {
+	fakeDocRepo
+	docs  []document.Document
+	near  map[int64][]int64
+	saved map[int64]int64
+}
+
+func (d *duplicateDocRepo) ListEmbeddingsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error) {
+	var out []document.Document
+	for _, doc := range d.docs {
+		if doc.ID > afterID && len(out) < limit {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+func (d *duplicateDocRepo) NearDuplicates(ctx context.Context, id int64, embedding []float32, maxDistance float64, limit int) ([]int64, error) {
+	return d.near[id], nil
+}
+
+func (d *duplicateDocRepo) SaveDuplicateSets(ctx context.Context, sets map[int64]int64) error {
+	d.saved = sets
+	return nil
+}
+
+func TestDetectDuplicatesPagesThroughCorpus(t *testing.T) {
+	docs := &duplicateDocRepo{
+		docs: []document.Document{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}},
+		near: map[int64][]int64{2: {4}, 4: {2}, 3: {5}, 5: {3}},
+	}
+	cfg := config.DefaultClusterConfig()
+	cfg.BatchSize = 2
+	svc, err := NewService(&fakeClusterRepo{}, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n, err := svc.DetectDuplicates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 duplicate sets, got %d", n)
+	}
+	want := map[int64]int64{2: 2, 4: 2, 3: 3, 5: 3}
+	if len(docs.saved) != len(want) {
+		t.Fatalf("expected %v, got %v", want, docs.saved)
+	}
+	for id, set := range want {
+		if docs.saved[id] != set {
+			t.Fatalf("expected %v, got %v", want, docs.saved)
+		}
+	}
+}
+
+func TestDetectDuplicatesDoesNotChain(t *testing.T) {
+	// 1~2 and 2~3 are near duplicates, but 1 and 3 are far apart: 3 must not
+	// join the set of 1 through 2.
+	docs := &duplicateDocRepo{
+		docs: []document.Document{{ID: 1}, {ID: 2}, {ID: 3}},
+		near: map[int64][]int64{1: {2}, 2: {1, 3}, 3: {2}},
+	}
+	svc, err := NewService(&fakeClusterRepo{}, docs, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n, err := svc.DetectDuplicates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(docs.saved) != 2 || docs.saved[1] != 1 || docs.saved[2] != 1 {
+		t.Fatalf("expected only {1, 2} in set 1, got %d sets %v", n, docs.saved)
+	}
+	if _, ok := docs.saved[3]; ok {
+		t.Fatalf("expected document 3 to stay outside the set, got %v", docs.saved)
+	}
+}
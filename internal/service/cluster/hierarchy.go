@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/models/cluster"
+)
+
+const maxTreeDepth = 10
+
+func (s *ClusterService) Children(ctx context.Context, id int64, limit, offset int) ([]cluster.Cluster, error) {
+	if s.clusterRepo == nil {
+		return nil, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	children, err := s.clusterRepo.ListChildren(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.clusterRepo.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sizes := subtreeSizes(nodes)
+	for i := range children {
+		children[i].Size = sizes[children[i].ID]
+	}
+	return children, nil
+}
+
+func (s *ClusterService) Tree(ctx context.Context, depth int) ([]cluster.TreeNode, error) {
+	if s.clusterRepo == nil {
+		return nil, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	if depth <= 0 || depth > maxTreeDepth {
+		depth = maxTreeDepth
+	}
+	nodes, err := s.clusterRepo.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(nodes, depth), nil
+}
+
+func subtreeSizes(nodes []cluster.Cluster) map[int64]int64 {
+	sizes := make(map[int64]int64, len(nodes))
+	parents := make(map[int64]*int64, len(nodes))
+	for _, n := range nodes {
+		parents[n.ID] = n.ParentID
+	}
+	for _, n := range nodes {
+		id := n.ID
+		seen := 0
+		for {
+			sizes[id] += n.Size
+			parent := parents[id]
+			if parent == nil || seen > len(nodes) {
+				break
+			}
+			id = *parent
+			seen++
+		}
+	}
+	return sizes
+}
+
+func buildTree(nodes []cluster.Cluster, depth int) []cluster.TreeNode {
+	sizes := subtreeSizes(nodes)
+	children := make(map[int64][]cluster.Cluster, len(nodes))
+	var roots []cluster.Cluster
+	for _, n := range nodes {
+		n.Size = sizes[n.ID]
+		if n.ParentID == nil {
+			roots = append(roots, n)
+			continue
+		}
+		children[*n.ParentID] = append(children[*n.ParentID], n)
+	}
+
+	var build func(items []cluster.Cluster, level int) []cluster.TreeNode
+	build = func(items []cluster.Cluster, level int) []cluster.TreeNode {
+		out := make([]cluster.TreeNode, 0, len(items))
+		for _, item := range items {
+			node := cluster.TreeNode{Cluster: item}
+			if level < depth {
+				node.Children = build(children[item.ID], level+1)
+			}
+			out = append(out, node)
+		}
+		return out
+	}
+	return build(roots, 1)
+}
@@ -0,0 +1,37 @@
+package cluster
+
+import (
+	"testing"
+
+	"NeoBIT/internal/models/cluster"
+)
+
+func TestBuildTree(t *testing.T) {
+	root := int64(1)
+	mid := int64(2)
+	nodes := []cluster.Cluster{
+		{ID: 1, Size: 0},
+		{ID: 2, ParentID: &root, Level: 1, Size: 0},
+		{ID: 3, ParentID: &root, Level: 1, Size: 4},
+		{ID: 4, ParentID: &mid, Level: 2, Size: 5},
+		{ID: 5, ParentID: &mid, Level: 2, Size: 6},
+		{ID: 6, Size: 7},
+	}
+
+	tree := buildTree(nodes, 2)
+	if len(tree) != 2 {
+		t.Fatalf("expected 2 roots, got %d", len(tree))
+	}
+	if tree[0].Size != 15 {
+		t.Fatalf("expected subtree size 15, got %d", tree[0].Size)
+	}
+	if len(tree[0].Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(tree[0].Children))
+	}
+	if tree[0].Children[0].Size != 11 {
+		t.Fatalf("expected child subtree size 11, got %d", tree[0].Children[0].Size)
+	}
+	if len(tree[0].Children[0].Children) != 0 {
+		t.Fatalf("expected depth limit to cut grandchildren")
+	}
+}
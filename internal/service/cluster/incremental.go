@@ -0,0 +1,265 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/vecmath"
+)
+
+func (s *ClusterService) assignIncremental(ctx context.Context, runID, seed int64, ids []int64, points [][]float32, metric Metric) (runSummary, error) {
+	buckets := make(map[int64][]int64)
+	pending := make(map[int64]int64)
+	spawned := 0
+	var noise []int64
+
+	index := make(map[int64]int)
+	var centroids [][]float32
+	var labels []int
+	var assignedPoints [][]float32
+	track := func(id int64, centroid, p []float32) {
+		c, ok := index[id]
+		if !ok {
+			c = len(centroids)
+			index[id] = c
+			centroids = append(centroids, nil)
+		}
+		centroids[c] = centroid
+		labels = append(labels, c)
+		assignedPoints = append(assignedPoints, p)
+	}
+
+	// Spawned clusters, centroid updates and assignments are written as one
+	// unit; on failure the whole batch is rolled back.
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		leaves, err := s.loadProjectedLeaves(ctx, metric)
+		if err != nil {
+			return err
+		}
+		for i, p := range points {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			var closest cluster.Cluster
+			var dist float64
+			if s.labelsNoise {
+				clusterID, ok, err := s.coreNeighborCluster(ctx, p, metric)
+				if err != nil {
+					return fmt.Errorf("core neighbour lookup: %w", err)
+				}
+				if !ok {
+					noise = append(noise, ids[i])
+					continue
+				}
+				closest, err = s.clusterByID(ctx, leaves, clusterID)
+			} else if leaves != nil {
+				closest, dist, err = leaves.nearest(p)
+			} else {
+				closest, dist, err = s.clusterRepo.Nearest(ctx, p, string(metric))
+			}
+			if err != nil {
+				return fmt.Errorf("nearest cluster lookup: %w", err)
+			}
+
+			if !s.labelsNoise && dist > s.cfg.SpawnDistance {
+				id, err := s.clusterRepo.Create(ctx, cluster.Cluster{
+					Algorithm: s.cfg.Algorithm,
+					Metric:    string(metric),
+					K:         s.cfg.K,
+					RunID:     &runID,
+					Centroid:  clone(p),
+				})
+				if err != nil {
+					return fmt.Errorf("spawn cluster: %w", err)
+				}
+				if leaves != nil {
+					leaves.put(cluster.Cluster{ID: id, Centroid: clone(p)})
+				}
+				spawned++
+				pending[id]++
+				buckets[id] = append(buckets[id], ids[i])
+				track(id, p, p)
+				continue
+			}
+
+			n := closest.Size + pending[closest.ID]
+			centroid := metric.project(runningMean(closest.Centroid, p, n))
+			if err := s.clusterRepo.UpdateCentroid(ctx, closest.ID, centroid); err != nil {
+				return fmt.Errorf("update centroid: %w", err)
+			}
+			if leaves != nil {
+				closest.Centroid = centroid
+				leaves.put(closest)
+			}
+			pending[closest.ID]++
+			buckets[closest.ID] = append(buckets[closest.ID], ids[i])
+			track(closest.ID, centroid, p)
+		}
+
+		for clusterID, docIDs := range buckets {
+			if err := s.assign(ctx, runID, docIDs, clusterID); err != nil {
+				return fmt.Errorf("update cluster ids: %w", err)
+			}
+		}
+		if err := s.markNoise(ctx, runID, noise); err != nil {
+			return fmt.Errorf("mark noise: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Error(ctx, "cluster worker: incremental batch rolled back", logger.FieldAny("run_id", runID), logger.FieldAny("error", err))
+		return runSummary{}, err
+	}
+
+	summary := runSummary{Docs: len(ids), Diagnostics: Diagnostics{Noise: len(noise)}}
+	s.log.Info(ctx, "cluster worker: assigned docs to existing clusters",
+		logger.FieldAny("docs", summary.Docs),
+		logger.FieldAny("clusters", len(buckets)),
+		logger.FieldAny("spawned", spawned),
+		logger.FieldAny("noise", len(noise)),
+	)
+
+	summary.K = len(centroids)
+	if len(assignedPoints) > 0 {
+		opts := Options{Rand: newRand(seed)}
+		quality := evaluate(assignedPoints, labels, centroids, metric, s.cfg.QualitySample, opts)
+		summary.Diagnostics.Inertia = quality.Inertia
+		s.recordQuality(ctx, runID, s.cfg.Algorithm, summary.K, len(assignedPoints), quality, nil)
+	}
+	return summary, nil
+}
+
+// coreNeighborCluster decides DBSCAN membership of p against the clustered
+// corpus the way expand does, rather than by the distance to a centroid,
+// which chained or non-convex clusters reach far beyond. A core point joins
+// the cluster of its nearest clustered neighbour within Eps; any other point
+// joins the cluster of the nearest such neighbour that is itself a core
+// point, and is noise when there is none. Neighbourhoods are counted on the
+// stored embeddings through the HNSW index, the space Eps is calibrated for,
+// even when the active generation was fitted on a projection.
+func (s *ClusterService) coreNeighborCluster(ctx context.Context, p []float32, metric Metric) (int64, bool, error) {
+	minPts := s.cfg.MinPts
+	if minPts <= 0 {
+		minPts = 5
+	}
+	neighbors, err := s.docRepo.ClusteredNeighbors(ctx, p, s.cfg.Eps, minPts, string(metric))
+	if err != nil {
+		return 0, false, err
+	}
+	if len(neighbors) == 0 {
+		return 0, false, nil
+	}
+	n, err := s.docRepo.CountNeighbors(ctx, p, s.cfg.Eps, minPts, string(metric))
+	if err != nil {
+		return 0, false, err
+	}
+	if n >= minPts {
+		return *neighbors[0].ClusterID, true, nil
+	}
+	for _, nb := range neighbors {
+		n, err := s.docRepo.CountNeighbors(ctx, nb.Embedding, s.cfg.Eps, minPts, string(metric))
+		if err != nil {
+			return 0, false, err
+		}
+		if n >= minPts {
+			return *nb.ClusterID, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// clusterByID returns cluster id with its current centroid, from the
+// in-memory leaves when they are loaded.
+func (s *ClusterService) clusterByID(ctx context.Context, leaves *projectedLeaves, id int64) (cluster.Cluster, error) {
+	if leaves != nil {
+		if i, ok := leaves.index[id]; ok {
+			return leaves.clusters[i], nil
+		}
+	}
+	return s.clusterRepo.Get(ctx, id)
+}
+
+func runningMean(centroid, p []float32, n int64) []float32 {
+	out := make([]float32, len(centroid))
+	w := 1 / float32(n+1)
+	for i := range centroid {
+		out[i] = centroid[i] + (p[i]-centroid[i])*w
+	}
+	return out
+}
+
+// projectedLeaves stands in for the repository's Nearest when the active
+// generation was fitted with a projection: leaf centroids are loaded once per
+// batch and searched in memory in the projected space.
+type projectedLeaves struct {
+	proj     *projection
+	metric   Metric
+	clusters []cluster.Cluster
+	index    map[int64]int
+	set      centroidSet
+}
+
+// loadProjectedLeaves returns nil when the active generation has no
+// projection.
+func (s *ClusterService) loadProjectedLeaves(ctx context.Context, metric Metric) (*projectedLeaves, error) {
+	stored, err := s.clusterRepo.ActiveProjection(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load projection: %w", err)
+	}
+	if stored == nil {
+		return nil, nil
+	}
+	proj, err := projectionFromModel(*stored)
+	if err != nil {
+		return nil, fmt.Errorf("load projection: %w", err)
+	}
+	clusters, err := s.clusterRepo.Leaves(ctx, string(metric))
+	if err != nil {
+		return nil, fmt.Errorf("load leaf clusters: %w", err)
+	}
+	l := &projectedLeaves{
+		proj:   proj,
+		metric: metric,
+		index:  make(map[int64]int, len(clusters)),
+		set:    centroidSet{metric: metric},
+	}
+	for _, c := range clusters {
+		l.put(c)
+	}
+	return l, nil
+}
+
+func (l *projectedLeaves) nearest(p []float32) (cluster.Cluster, float64, error) {
+	if len(l.clusters) == 0 {
+		return cluster.Cluster{}, 0, fmt.Errorf("no clusters to assign to")
+	}
+	c, dist := l.set.nearest(l.project(p))
+	return l.clusters[c], float64(dist), nil
+}
+
+// put adds a cluster or replaces the centroid of a known one.
+func (l *projectedLeaves) put(c cluster.Cluster) {
+	projected := l.project(c.Centroid)
+	i, ok := l.index[c.ID]
+	if !ok {
+		i = len(l.clusters)
+		l.index[c.ID] = i
+		l.clusters = append(l.clusters, c)
+		l.set.centroids = append(l.set.centroids, nil)
+		l.set.norms = append(l.set.norms, 0)
+	}
+	l.clusters[i] = c
+	l.set.centroids[i] = projected
+	l.set.norms[i] = vecmath.Norm(projected)
+}
+
+func (l *projectedLeaves) project(p []float32) []float32 {
+	projected := l.proj.apply(p)
+	if l.metric.spherical() {
+		normalize(projected)
+	}
+	return projected
+}
@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"context"
+	"math"
+	"sort"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/models/document"
+)
+
+type memClusterRepo struct {
+	fakeClusterRepo
+	clusters []cluster.Cluster
+}
+
+func (m *memClusterRepo) Create(ctx context.Context, c cluster.Cluster) (int64, error) {
+	c.ID = int64(len(m.clusters) + 1)
+	m.clusters = append(m.clusters, c)
+	return c.ID, nil
+}
+
+func (m *memClusterRepo) Count(ctx context.Context, metric string) (int64, error) {
+	return int64(len(m.clusters)), nil
+}
+
+func (m *memClusterRepo) Nearest(ctx context.Context, embedding []float32, metric string) (cluster.Cluster, float64, error) {
+	best := -1
+	bestDist := math.MaxFloat64
+	for i, c := range m.clusters {
+		if d := float64(distance(embedding, c.Centroid)); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return m.clusters[best], bestDist, nil
+}
+
+func (m *memClusterRepo) UpdateCentroid(ctx context.Context, id int64, centroid []float32) error {
+	m.clusters[id-1].Centroid = centroid
+	return nil
+}
+
+type recordingDocRepo struct {
+	fakeDocRepo
+	assigned map[int64]int64
+}
+
+func (r *recordingDocRepo) UpdateClusterIDs(ctx context.Context, ids []int64, clusterID int64) error {
+	for _, id := range ids {
+		r.assigned[id] = clusterID
+	}
+	return nil
+}
+
+func TestAssignIncremental(t *testing.T) {
+	clusters := &memClusterRepo{clusters: []cluster.Cluster{{ID: 1, Centroid: []float32{0, 0}, Size: 1}}}
+	docs := &recordingDocRepo{assigned: map[int64]int64{}}
+	cfg := config.DefaultClusterConfig()
+	cfg.SpawnDistance = 5
+	svc, err := NewService(clusters, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary, err := svc.assignIncremental(context.Background(), 1, 1, []int64{10, 11}, [][]float32{{2, 0}, {100, 100}}, MetricL2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Docs != 2 || summary.K != 2 {
+		t.Fatalf("expected 2 docs in 2 clusters, got %+v", summary)
+	}
+
+	if docs.assigned[10] != 1 {
+		t.Fatalf("expected doc 10 in existing cluster, got %d", docs.assigned[10])
+	}
+	if got := clusters.clusters[0].Centroid; got[0] != 1 || got[1] != 0 {
+		t.Fatalf("expected running mean centroid [1 0], got %v", got)
+	}
+	if len(clusters.clusters) != 2 || docs.assigned[11] != 2 {
+		t.Fatalf("expected far doc to spawn a new cluster, got %d clusters and assignment %d", len(clusters.clusters), docs.assigned[11])
+	}
+}
+
+type densityClusterRepo struct {
+	memClusterRepo
+}
+
+func (d *densityClusterRepo) Get(ctx context.Context, id int64) (cluster.Cluster, error) {
+	return d.clusters[id-1], nil
+}
+
+// densityDocRepo answers neighbourhood queries over an in-memory corpus with
+// L2 distances.
+type densityDocRepo struct {
+	recordingDocRepo
+	corpus []document.Document
+}
+
+func (d *densityDocRepo) within(embedding []float32, radius float64) []document.Document {
+	var out []document.Document
+	for _, doc := range d.corpus {
+		if float64(distance(embedding, doc.Embedding)) <= radius {
+			out = append(out, doc)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return distance(embedding, out[i].Embedding) < distance(embedding, out[j].Embedding)
+	})
+	return out
+}
+
+func (d *densityDocRepo) CountNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) (int, error) {
+	return min(len(d.within(embedding, radius)), limit), nil
+}
+
+func (d *densityDocRepo) ClusteredNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) ([]document.Document, error) {
+	var out []document.Document
+	for _, doc := range d.within(embedding, radius) {
+		if doc.ClusterID != nil && len(out) < limit {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+func TestAssignIncrementalDBSCANFollowsCorePoints(t *testing.T) {
+	// Cluster 1 is a line from (-10, 0) to (10, 0): its centroid sits at the
+	// origin, far more than eps from either end.
+	clusterID := int64(1)
+	docs := &densityDocRepo{recordingDocRepo: recordingDocRepo{assigned: map[int64]int64{}}}
+	for x := -10; x <= 10; x++ {
+		docs.corpus = append(docs.corpus, document.Document{ID: int64(x + 100), ClusterID: &clusterID, Embedding: []float32{float32(x), 0}})
+	}
+	near, far := []float32{10.5, 0}, []float32{30, 0}
+	docs.corpus = append(docs.corpus, document.Document{ID: 200, Embedding: near}, document.Document{ID: 201, Embedding: far})
+
+	clusters := &densityClusterRepo{memClusterRepo{clusters: []cluster.Cluster{{ID: 1, Centroid: []float32{0, 0}, Size: 21}}}}
+	cfg := config.DefaultClusterConfig()
+	cfg.Algorithm = "dbscan"
+	cfg.Eps = 1.5
+	cfg.MinPts = 3
+	svc, err := NewService(clusters, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary, err := svc.assignIncremental(context.Background(), 1, 1, []int64{200, 201}, [][]float32{near, far}, MetricL2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if docs.assigned[200] != 1 {
+		t.Fatalf("expected the document at the far end of the line to join cluster 1, got %v", docs.assigned)
+	}
+	if _, ok := docs.assigned[201]; ok || summary.Diagnostics.Noise != 1 {
+		t.Fatalf("expected the isolated document to be noise, got %v and %d noise", docs.assigned, summary.Diagnostics.Noise)
+	}
+}
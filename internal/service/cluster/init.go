@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"math/rand"
+	"time"
+
+	"NeoBIT/internal/vecmath"
+)
+
+const (
+	initRandom         = "random"
+	initKMeansPlusPlus = "kmeans++"
+	initKMeansParallel = "kmeans||"
+
+	kmeansParallelMinPoints = 10000
+	kmeansParallelRounds    = 5
+)
+
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(resolveSeed(seed)))
+}
+
+// resolveSeed turns the "random" seed 0 into a concrete one so that it can be
+// recorded and the run reproduced.
+func resolveSeed(seed int64) int64 {
+	if seed == 0 {
+		return time.Now().UnixNano()
+	}
+	return seed
+}
+
+func initCentroids(points [][]float32, k int, method string, rnd *rand.Rand) [][]float32 {
+	switch method {
+	case initRandom:
+		return randomCentroids(points, k, rnd)
+	case initKMeansParallel:
+		if len(points) >= kmeansParallelMinPoints {
+			return kmeansParallel(points, k, rnd)
+		}
+		return kmeansPlusPlus(points, nil, k, rnd)
+	default:
+		return kmeansPlusPlus(points, nil, k, rnd)
+	}
+}
+
+// kmeansPlusPlus picks the first centroid uniformly and every next one with
+// probability proportional to weight * D(x)^2. A nil weights slice means
+// every point has weight 1.
+func kmeansPlusPlus(points [][]float32, weights []float64, k int, rnd *rand.Rand) [][]float32 {
+	centroids := make([][]float32, 0, k)
+	centroids = append(centroids, clone(points[weightedChoice(weights, len(points), rnd)]))
+
+	d2 := make([]float64, len(points))
+	vecmath.ParallelFor(len(points), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			d2[i] = float64(vecmath.SquaredL2(points[i], centroids[0]))
+		}
+	})
+
+	scores := make([]float64, len(points))
+	for len(centroids) < k {
+		for i := range scores {
+			scores[i] = d2[i]
+			if weights != nil {
+				scores[i] *= weights[i]
+			}
+		}
+		idx := weightedChoice(scores, len(points), rnd)
+		centroid := clone(points[idx])
+		centroids = append(centroids, centroid)
+
+		vecmath.ParallelFor(len(points), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				d2[i] = min(d2[i], float64(vecmath.SquaredL2(points[i], centroid)))
+			}
+		})
+	}
+	return centroids
+}
+
+// kmeansParallel implements k-means|| (Bahmani et al., 2012): a few
+// oversampling rounds collect O(k log n) candidates, which are then reduced
+// to k centroids with weighted k-means++.
+func kmeansParallel(points [][]float32, k int, rnd *rand.Rand) [][]float32 {
+	candidates := [][]float32{clone(points[rnd.Intn(len(points))])}
+	d2 := make([]float64, len(points))
+	vecmath.ParallelFor(len(points), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			d2[i] = float64(vecmath.SquaredL2(points[i], candidates[0]))
+		}
+	})
+	cost := sum(d2)
+
+	oversampling := float64(2 * k)
+	for round := 0; round < kmeansParallelRounds && cost > 0; round++ {
+		added := len(candidates)
+		for i, p := range points {
+			if rnd.Float64() < oversampling*d2[i]/cost {
+				candidates = append(candidates, clone(p))
+			}
+		}
+		fresh := candidates[added:]
+		vecmath.ParallelFor(len(points), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				for _, c := range fresh {
+					d2[i] = min(d2[i], float64(vecmath.SquaredL2(points[i], c)))
+				}
+			}
+		})
+		// Summed serially so that a seeded run stays reproducible.
+		cost = sum(d2)
+	}
+
+	if len(candidates) <= k {
+		return kmeansPlusPlus(points, nil, k, rnd)
+	}
+
+	weights := make([]float64, len(candidates))
+	nearestIdx := make([]int, len(points))
+	newCentroidSet(candidates, MetricL2).assign(points, nearestIdx, nil)
+	for _, c := range nearestIdx {
+		weights[c]++
+	}
+	return kmeansPlusPlus(candidates, weights, k, rnd)
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func weightedChoice(weights []float64, n int, rnd *rand.Rand) int {
+	if weights == nil {
+		return rnd.Intn(n)
+	}
+	total := sum(weights)
+	if total <= 0 {
+		return rnd.Intn(n)
+	}
+	target := rnd.Float64() * total
+	for i, w := range weights {
+		target -= w
+		if target < 0 {
+			return i
+		}
+	}
+	return n - 1
+}
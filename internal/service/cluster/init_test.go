@@ -0,0 +1,53 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func blobs(n int) [][]float32 {
+	rnd := newRand(7)
+	centers := [][]float32{{0, 0}, {20, 0}, {0, 20}}
+	points := make([][]float32, 0, n*len(centers))
+	for _, c := range centers {
+		for i := 0; i < n; i++ {
+			points = append(points, []float32{c[0] + rnd.Float32(), c[1] + rnd.Float32()})
+		}
+	}
+	return points
+}
+
+func TestInitCentroidsDeterministicWithSeed(t *testing.T) {
+	points := blobs(50)
+	for _, method := range []string{initRandom, initKMeansPlusPlus, initKMeansParallel} {
+		a := initCentroids(points, 3, method, newRand(1))
+		b := initCentroids(points, 3, method, newRand(1))
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("%s: expected identical centroids for the same seed", method)
+		}
+	}
+}
+
+func TestKMeansPlusPlusSpreadsCentroids(t *testing.T) {
+	points := blobs(50)
+	centroids := kmeansPlusPlus(points, nil, 3, newRand(3))
+	seen := make(map[int]struct{}, 3)
+	for _, c := range centroids {
+		seen[int(c[0]/10)*10+int(c[1]/10)] = struct{}{}
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected one centroid per blob, got %v", centroids)
+	}
+}
+
+func TestKMeansParallelReturnsK(t *testing.T) {
+	points := blobs(kmeansParallelMinPoints / 3)
+	centroids := kmeansParallel(points, 3, newRand(5))
+	if len(centroids) != 3 {
+		t.Fatalf("expected 3 centroids, got %d", len(centroids))
+	}
+	res := kmeans(points, centroids, MetricL2, 20, 1e-6)
+	if res.inertia/float64(len(points)) > 1 {
+		t.Fatalf("expected tight clusters, got mean inertia %f", res.inertia/float64(len(points)))
+	}
+}
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"context"
+	"time"
 
 	"NeoBIT/internal/models/cluster"
 	"NeoBIT/internal/models/document"
@@ -10,11 +11,73 @@ import (
 type ClusterRepository interface {
 	Create(ctx context.Context, cluster cluster.Cluster) (int64, error)
 	List(ctx context.Context, limit, offset int) ([]cluster.Cluster, error)
+	Count(ctx context.Context, metric string) (int64, error)
+	Nearest(ctx context.Context, embedding []float32, metric string) (cluster.Cluster, float64, error)
+	UpdateCentroid(ctx context.Context, id int64, centroid []float32) error
+	ListChildren(ctx context.Context, parentID int64, limit, offset int) ([]cluster.Cluster, error)
+	ListNodes(ctx context.Context) ([]cluster.Cluster, error)
+	LiveIDs(ctx context.Context) ([]int64, error)
+	SaveQuality(ctx context.Context, q cluster.Quality) (int64, error)
 	SizeStats(ctx context.Context) (min float64, max float64, avg float64, err error)
+	CreateRun(ctx context.Context, run cluster.Run) (int64, error)
+	FinishRun(ctx context.Context, run cluster.Run) error
+	GetRun(ctx context.Context, id int64) (cluster.Run, error)
+	ListRuns(ctx context.Context, limit, offset int) ([]cluster.Run, error)
+	SaveAssignments(ctx context.Context, runID int64, docIDs []int64, clusterID *int64) error
+	Rollback(ctx context.Context, rollbackID, targetID int64) (int64, error)
+	CreateGeneration(ctx context.Context, runID int64) (int64, error)
+	ActivateGeneration(ctx context.Context, generationID, runID int64) (int64, error)
+	DiscardGeneration(ctx context.Context, generationID int64) error
+	PruneGenerations(ctx context.Context, keep int) (int64, error)
+	DeleteEmpty(ctx context.Context) (int64, error)
+	Get(ctx context.Context, id int64) (cluster.Cluster, error)
+	CentroidStats(ctx context.Context) ([]cluster.CentroidStat, error)
+	UpdateCentroids(ctx context.Context, updates []cluster.CentroidUpdate) error
+	PruneDriftHistory(ctx context.Context, keep int) (int64, error)
+	DriftHistory(ctx context.Context, clusterID int64, limit int) ([]cluster.DriftPoint, error)
+	SaveLabels(ctx context.Context, labels []cluster.Label) error
+	PatchLabel(ctx context.Context, id int64, patch cluster.LabelPatch) error
+	MergeClusters(ctx context.Context, runID, sourceID, targetID int64) (int64, error)
+	CentroidStat(ctx context.Context, clusterID int64) (cluster.CentroidStat, error)
+	ScoreOutliers(ctx context.Context, z float64) (int64, error)
+	Leaves(ctx context.Context, metric string) ([]cluster.Cluster, error)
+	SaveProjection(ctx context.Context, p cluster.Projection) (int64, error)
+	ActiveProjection(ctx context.Context) (*cluster.Projection, error)
+	Overlap(ctx context.Context, by string, a, b int64) ([]cluster.OverlapCell, error)
+	ClusteringExists(ctx context.Context, by string, id int64) (bool, error)
 }
 
 type DocumentRepository interface {
-	ListUnclustered(ctx context.Context, limit int) ([]document.Document, error)
+	ClaimUnclustered(ctx context.Context, limit int, lease time.Duration) ([]document.Document, error)
+	ReleaseLeases(ctx context.Context, ids []int64) error
 	UpdateClusterIDs(ctx context.Context, ids []int64, clusterID int64) error
 	PctClustered(ctx context.Context) (float64, error)
+	CountUnclustered(ctx context.Context) (int64, error)
+	Count(ctx context.Context) (int64, error)
+	SampleEmbeddings(ctx context.Context, fraction float64, limit int) ([][]float32, error)
+	CountNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) (int, error)
+	ClusteredNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) ([]document.Document, error)
+	MarkNoise(ctx context.Context, ids []int64) error
+	ListEmbeddingsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error)
+	ListLabelTexts(ctx context.Context, perCluster int) ([]document.Document, error)
+	ListTextsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error)
+	ListClusterEmbeddings(ctx context.Context, clusterID int64) ([]document.Document, error)
+	NearDuplicates(ctx context.Context, id int64, embedding []float32, maxDistance float64, limit int) ([]int64, error)
+	SaveDuplicateSets(ctx context.Context, sets map[int64]int64) error
+	SaveMapCoordinates(ctx context.Context, ids []int64, xs, ys []float32) error
+	RankMapPoints(ctx context.Context) (int64, error)
+}
+
+// Locker coordinates instances through advisory locks. The returned function
+// releases the lock.
+type Locker interface {
+	Lock(ctx context.Context, key int64) (func(), error)
+	LockShared(ctx context.Context, key int64) (func(), error)
+	TryLock(ctx context.Context, key int64) (func(), bool, error)
+}
+
+// Transactor runs fn as a unit of work; repositories called with the ctx it
+// passes join the same transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -0,0 +1,343 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+const maxFinishedJobs = 100
+
+var (
+	ErrJobNotFound   = errors.New("cluster job not found")
+	ErrJobRunning    = errors.New("a cluster job is already running")
+	ErrJobNotRunning = errors.New("cluster job is not running")
+	ErrInvalidJob    = errors.New("invalid cluster job")
+)
+
+// jobState tracks a running job. advance and addRun do nothing on a nil
+// receiver so that the service can report progress unconditionally.
+type jobState struct {
+	mu     sync.Mutex
+	job    cluster.Job
+	cancel context.CancelFunc
+}
+
+func (j *jobState) advance(docs int) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.job.DocsProcessed += int64(docs)
+	j.mu.Unlock()
+}
+
+func (j *jobState) addRun(runID int64) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.job.RunIDs = append(j.job.RunIDs, runID)
+	j.mu.Unlock()
+}
+
+func (j *jobState) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.job.FinishedAt = &now
+	switch {
+	case err == nil:
+		j.job.Status = cluster.JobStatusSucceeded
+	case errors.Is(err, context.Canceled):
+		j.job.Status = cluster.JobStatusCancelled
+	default:
+		msg := err.Error()
+		j.job.Status = cluster.JobStatusFailed
+		j.job.Error = &msg
+	}
+}
+
+func (j *jobState) snapshot(now time.Time) cluster.Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job := j.job
+	job.RunIDs = append([]int64{}, j.job.RunIDs...)
+	if job.Status == cluster.JobStatusRunning && job.DocsProcessed > 0 && job.DocsTotal > job.DocsProcessed {
+		perDoc := now.Sub(job.StartedAt).Seconds() / float64(job.DocsProcessed)
+		eta := perDoc * float64(job.DocsTotal-job.DocsProcessed)
+		job.ETASeconds = &eta
+	}
+	return job
+}
+
+// JobManager runs operator-triggered clustering jobs in the background, one
+// at a time. Jobs are bound to the context passed to NewJobManager, so they
+// stop with the server; finished jobs are kept in memory for inspection.
+type JobManager struct {
+	ctx    context.Context
+	svc    *ClusterService
+	log    logger.Logger
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	nextID int64
+	jobs   map[int64]*jobState
+	active *jobState
+	// starting reserves the single job slot while Start waits for the
+	// database outside mu.
+	starting bool
+}
+
+func NewJobManager(ctx context.Context, svc *ClusterService, log logger.Logger) *JobManager {
+	if log == nil {
+		log = logger.Nop()
+	}
+	return &JobManager{ctx: ctx, svc: svc, log: log, jobs: make(map[int64]*jobState)}
+}
+
+func (m *JobManager) Start(req cluster.JobRequest) (cluster.Job, error) {
+	if m.svc == nil {
+		return cluster.Job{}, fmt.Errorf("cluster jobs: service is nil")
+	}
+	if req.Scope == "" {
+		req.Scope = cluster.JobScopeFull
+	}
+	switch req.Scope {
+	case cluster.JobScopeFull, cluster.JobScopeIncremental, cluster.JobScopeCentroids, cluster.JobScopeLabels,
+		cluster.JobScopeDuplicates, cluster.JobScopeOutliers, cluster.JobScopeMap:
+	default:
+		return cluster.Job{}, fmt.Errorf("%w: unknown scope %q", ErrInvalidJob, req.Scope)
+	}
+	if req.K < 0 {
+		return cluster.Job{}, fmt.Errorf("%w: k must not be negative", ErrInvalidJob)
+	}
+
+	cfg := m.svc.cfg
+	if req.Algorithm != "" {
+		cfg.Algorithm = req.Algorithm
+	}
+	if req.K > 0 {
+		cfg.K = req.K
+		cfg.AutoK = false
+	}
+	if req.AutoK != nil {
+		cfg.AutoK = *req.AutoK
+	}
+	svc, err := m.svc.withConfig(cfg)
+	if err != nil {
+		return cluster.Job{}, fmt.Errorf("%w: %v", ErrInvalidJob, err)
+	}
+
+	m.mu.Lock()
+	if m.active != nil || m.starting {
+		m.mu.Unlock()
+		return cluster.Job{}, ErrJobRunning
+	}
+	m.starting = true
+	m.mu.Unlock()
+
+	// A full recluster started by the scheduler or another instance would
+	// only fail the job later, so it is turned away here. The advisory lock
+	// is a database round trip, taken without mu so that Get and Cancel stay
+	// responsive.
+	var release func()
+	if req.Scope == cluster.JobScopeFull {
+		release, err = svc.lockRecluster(m.ctx)
+		if err != nil {
+			m.mu.Lock()
+			m.starting = false
+			m.mu.Unlock()
+			return cluster.Job{}, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.starting = false
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.nextID++
+	state := &jobState{
+		job: cluster.Job{
+			ID:        m.nextID,
+			Scope:     req.Scope,
+			Algorithm: cfg.Algorithm,
+			K:         cfg.K,
+			AutoK:     cfg.AutoK,
+			Status:    cluster.JobStatusRunning,
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	svc.job = state
+	m.jobs[state.job.ID] = state
+	m.active = state
+	m.prune()
+
+	m.wg.Add(1)
+	go m.run(ctx, svc, state, release)
+	return state.snapshot(time.Now()), nil
+}
+
+func (m *JobManager) Get(id int64) (cluster.Job, error) {
+	m.mu.Lock()
+	state, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return cluster.Job{}, ErrJobNotFound
+	}
+	return state.snapshot(time.Now()), nil
+}
+
+// Cancel asks a running job to stop. The job observes the cancellation at
+// its next checkpoint, so its status may stay running for a short while.
+func (m *JobManager) Cancel(id int64) (cluster.Job, error) {
+	m.mu.Lock()
+	state, ok := m.jobs[id]
+	active := state == m.active
+	m.mu.Unlock()
+	if !ok {
+		return cluster.Job{}, ErrJobNotFound
+	}
+	if !active {
+		return state.snapshot(time.Now()), ErrJobNotRunning
+	}
+	state.cancel()
+	return state.snapshot(time.Now()), nil
+}
+
+// Wait blocks until every started job has returned.
+func (m *JobManager) Wait() {
+	m.wg.Wait()
+}
+
+// run executes the job; release, set for full reclusters, frees the lock
+// Start took for it.
+func (m *JobManager) run(ctx context.Context, svc *ClusterService, state *jobState, release func()) {
+	defer m.wg.Done()
+	defer state.cancel()
+
+	var err error
+	switch state.job.Scope {
+	case cluster.JobScopeFull:
+		err = m.runFull(ctx, svc, state, release)
+	case cluster.JobScopeIncremental:
+		err = m.runIncremental(ctx, svc, state)
+	case cluster.JobScopeCentroids:
+		_, err = svc.RecomputeCentroids(ctx)
+	case cluster.JobScopeLabels:
+		_, err = svc.LabelClusters(ctx)
+	case cluster.JobScopeDuplicates:
+		err = m.runDuplicates(ctx, svc, state)
+	case cluster.JobScopeOutliers:
+		_, err = svc.ScoreOutliers(ctx)
+	case cluster.JobScopeMap:
+		err = m.runMap(ctx, svc, state)
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	state.finish(err)
+
+	m.mu.Lock()
+	m.active = nil
+	m.mu.Unlock()
+
+	job := state.snapshot(time.Now())
+	m.log.Info(ctx, "cluster job finished",
+		logger.FieldAny("job_id", job.ID),
+		logger.FieldAny("scope", job.Scope),
+		logger.FieldAny("status", job.Status),
+		logger.FieldAny("docs", job.DocsProcessed),
+	)
+}
+
+func (m *JobManager) runFull(ctx context.Context, svc *ClusterService, state *jobState, release func()) error {
+	defer release()
+	total, err := svc.docRepo.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count documents: %w", err)
+	}
+	state.mu.Lock()
+	state.job.DocsTotal = total
+	state.mu.Unlock()
+
+	_, err = svc.reclusterLocked(ctx)
+	return err
+}
+
+// runIncremental drains the unclustered backlog batch by batch.
+func (m *JobManager) runIncremental(ctx context.Context, svc *ClusterService, state *jobState) error {
+	total, err := svc.docRepo.CountUnclustered(ctx)
+	if err != nil {
+		return fmt.Errorf("count unclustered documents: %w", err)
+	}
+	state.mu.Lock()
+	state.job.DocsTotal = total
+	state.mu.Unlock()
+
+	for ctx.Err() == nil {
+		n, err := svc.processBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		state.advance(n)
+	}
+	return ctx.Err()
+}
+
+func (m *JobManager) runDuplicates(ctx context.Context, svc *ClusterService, state *jobState) error {
+	total, err := svc.docRepo.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count documents: %w", err)
+	}
+	state.mu.Lock()
+	state.job.DocsTotal = total
+	state.mu.Unlock()
+
+	_, err = svc.DetectDuplicates(ctx)
+	return err
+}
+
+func (m *JobManager) runMap(ctx context.Context, svc *ClusterService, state *jobState) error {
+	total, err := svc.docRepo.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count documents: %w", err)
+	}
+	state.mu.Lock()
+	state.job.DocsTotal = total
+	state.mu.Unlock()
+
+	_, err = svc.ProjectMap(ctx)
+	return err
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs. Callers must
+// hold m.mu.
+func (m *JobManager) prune() {
+	if len(m.jobs) <= maxFinishedJobs {
+		return
+	}
+	ids := make([]int64, 0, len(m.jobs))
+	for id := range m.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if len(m.jobs) <= maxFinishedJobs {
+			return
+		}
+		if m.jobs[id] != m.active {
+			delete(m.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/models/document"
+)
+
+type drainingDocRepo struct {
+	recordingDocRepo
+	docs []document.Document
+}
+
+func (d *drainingDocRepo) ClaimUnclustered(ctx context.Context, limit int, lease time.Duration) ([]document.Document, error) {
+	var out []document.Document
+	for _, doc := range d.docs {
+		if _, ok := d.assigned[doc.ID]; !ok && len(out) < limit {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+func (d *drainingDocRepo) CountUnclustered(ctx context.Context) (int64, error) {
+	return int64(len(d.docs) - len(d.assigned)), nil
+}
+
+func waitJob(t *testing.T, m *JobManager, id int64) cluster.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if job.Status != cluster.JobStatusRunning {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %d did not finish", id)
+	return cluster.Job{}
+}
+
+func TestJobManagerIncremental(t *testing.T) {
+	clusters := &runRecordingRepo{assignments: map[int64]map[int64]*int64{}}
+	docs := &drainingDocRepo{recordingDocRepo: recordingDocRepo{assigned: map[int64]int64{}}}
+	for i := int64(1); i <= 5; i++ {
+		docs.docs = append(docs.docs, document.Document{ID: i, Embedding: []float32{float32(i), 0}})
+	}
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "l2"
+	cfg.BatchSize = 2
+	cfg.SpawnDistance = 100
+	svc, err := NewService(clusters, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewJobManager(context.Background(), svc, logger.Nop())
+
+	job, err := m.Start(cluster.JobRequest{Scope: cluster.JobScopeIncremental, K: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != cluster.JobStatusRunning || job.Scope != cluster.JobScopeIncremental || job.K != 2 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	job = waitJob(t, m, job.ID)
+	m.Wait()
+
+	if job.Status != cluster.JobStatusSucceeded || job.DocsProcessed != 5 || job.DocsTotal != 5 {
+		t.Fatalf("unexpected finished job: %+v", job)
+	}
+	if len(job.RunIDs) != 3 {
+		t.Fatalf("expected 3 runs for 5 docs in batches of 2, got %v", job.RunIDs)
+	}
+	if clusters.runs[0].K != 2 {
+		t.Fatalf("expected k override to reach the run, got %d", clusters.runs[0].K)
+	}
+	if len(docs.assigned) != 5 {
+		t.Fatalf("expected all documents assigned, got %d", len(docs.assigned))
+	}
+}
+
+func TestJobManagerValidation(t *testing.T) {
+	svc, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewJobManager(context.Background(), svc, logger.Nop())
+
+	if _, err := m.Start(cluster.JobRequest{Scope: "everything"}); !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected invalid scope error, got %v", err)
+	}
+	if _, err := m.Start(cluster.JobRequest{Algorithm: "nope"}); !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected invalid algorithm error, got %v", err)
+	}
+	if _, err := m.Get(42); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestJobManagerCancel(t *testing.T) {
+	svc, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewJobManager(context.Background(), svc, logger.Nop())
+
+	state := &jobState{job: cluster.Job{ID: 7, Status: cluster.JobStatusRunning}}
+	ctx, cancel := context.WithCancel(context.Background())
+	state.cancel = cancel
+	m.jobs[7] = state
+	m.active = state
+
+	if _, err := m.Start(cluster.JobRequest{}); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("expected job running error, got %v", err)
+	}
+	if _, err := m.Cancel(7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Fatalf("expected job context cancelled")
+	}
+	state.finish(ctx.Err())
+	m.active = nil
+	if _, err := m.Cancel(7); !errors.Is(err, ErrJobNotRunning) {
+		t.Fatalf("expected not running error, got %v", err)
+	}
+	if job, _ := m.Get(7); job.Status != cluster.JobStatusCancelled {
+		t.Fatalf("expected cancelled status, got %s", job.Status)
+	}
+}
+
+func TestJobManagerRejectsFullWhileReclusterRuns(t *testing.T) {
+	svc, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewJobManager(context.Background(), svc, logger.Nop())
+
+	svc.reclusterMu.Lock()
+	if _, err := m.Start(cluster.JobRequest{Scope: cluster.JobScopeFull}); !errors.Is(err, ErrReclusterRunning) {
+		t.Fatalf("expected recluster running error, got %v", err)
+	}
+	if len(m.jobs) != 0 || m.active != nil {
+		t.Fatalf("expected no job registered, got %d jobs", len(m.jobs))
+	}
+	svc.reclusterMu.Unlock()
+}
+
+// blockingLocker holds TryLock until release is closed, like a slow database.
+type blockingLocker struct {
+	recordingLocker
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (l *blockingLocker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	close(l.entered)
+	<-l.release
+	return func() {}, false, nil
+}
+
+func TestJobManagerStartDoesNotHoldMutexAcrossLock(t *testing.T) {
+	locker := &blockingLocker{entered: make(chan struct{}), release: make(chan struct{})}
+	svc, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, locker, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewJobManager(context.Background(), svc, logger.Nop())
+
+	started := make(chan error, 1)
+	go func() {
+		_, err := m.Start(cluster.JobRequest{Scope: cluster.JobScopeFull})
+		started <- err
+	}()
+	<-locker.entered
+
+	if _, err := m.Get(1); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected Get to answer while the lock is pending, got %v", err)
+	}
+	if _, err := m.Start(cluster.JobRequest{Scope: cluster.JobScopeLabels}); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("expected the reserved slot to turn other jobs away, got %v", err)
+	}
+
+	close(locker.release)
+	if err := <-started; !errors.Is(err, ErrReclusterRunning) {
+		t.Fatalf("expected recluster running error, got %v", err)
+	}
+	if m.starting || m.active != nil || len(m.jobs) != 0 {
+		t.Fatalf("expected the reservation to be rolled back, got starting=%v active=%v jobs=%d", m.starting, m.active, len(m.jobs))
+	}
+}
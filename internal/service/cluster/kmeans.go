@@ -0,0 +1,147 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/vecmath"
+)
+
+type kmeansClusterer struct {
+	centroidModel
+	opts Options
+}
+
+func newKMeansClusterer(opts Options) Clusterer {
+	return &kmeansClusterer{centroidModel: centroidModel{metric: opts.Metric}, opts: opts}
+}
+
+func (c *kmeansClusterer) Fit(ctx context.Context, points [][]float32) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, fmt.Errorf("kmeans: no points")
+	}
+	k := clampK(c.opts.K, len(points))
+	init := initCentroids(points, k, c.opts.Config.Init, c.opts.Rand)
+	res := kmeans(points, init, c.opts.Metric, c.opts.Config.MaxIterations, c.opts.Config.Tolerance)
+	c.centroids = res.centroids
+	return Result{
+		Assignments: res.assignments,
+		Centroids:   res.centroids,
+		Diagnostics: Diagnostics{
+			Iterations: res.iterations,
+			Converged:  res.converged,
+			Inertia:    res.inertia,
+		},
+	}, nil
+}
+
+type kmeansResult struct {
+	assignments []int
+	centroids   [][]float32
+	inertia     float64
+	iterations  int
+	converged   bool
+}
+
+func kmeans(points [][]float32, centroids [][]float32, metric Metric, maxIterations int, tolerance float64) kmeansResult {
+	if maxIterations <= 0 {
+		maxIterations = 20
+	}
+
+	k := len(centroids)
+	assignments := make([]int, len(points))
+	dists := make([]float32, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+
+	res := kmeansResult{}
+	for iter := 1; iter <= maxIterations; iter++ {
+		res.iterations = iter
+		changed := newCentroidSet(centroids, metric).assign(points, assignments, dists)
+
+		next := updateCentroids(points, assignments, k, len(points[0]))
+		fillEmptyClusters(points, assignments, dists, next)
+		for c := range next {
+			next[c] = metric.project(next[c])
+		}
+
+		shift := maxShift(centroids, next)
+		centroids = next
+		if changed == 0 || shift <= tolerance {
+			res.converged = true
+			break
+		}
+	}
+
+	newCentroidSet(centroids, metric).assign(points, assignments, nil)
+	res.assignments = assignments
+	res.centroids = centroids
+	res.inertia = inertia(points, assignments, centroids)
+	return res
+}
+
+func updateCentroids(points [][]float32, assignments []int, k, dim int) [][]float32 {
+	sums := make([][]float64, k)
+	counts := make([]int, k)
+	for c := range sums {
+		sums[c] = make([]float64, dim)
+	}
+	for i, p := range points {
+		c := assignments[i]
+		counts[c]++
+		for j, v := range p {
+			sums[c][j] += float64(v)
+		}
+	}
+
+	centroids := make([][]float32, k)
+	for c := range centroids {
+		if counts[c] == 0 {
+			continue
+		}
+		centroid := make([]float32, dim)
+		for j := range centroid {
+			centroid[j] = float32(sums[c][j] / float64(counts[c]))
+		}
+		centroids[c] = centroid
+	}
+	return centroids
+}
+
+// fillEmptyClusters reseeds every empty cluster with the point that is
+// currently worst served by its centroid, so k stays constant.
+func fillEmptyClusters(points [][]float32, assignments []int, dists []float32, centroids [][]float32) {
+	for c := range centroids {
+		if centroids[c] != nil {
+			continue
+		}
+		far := 0
+		for i := range dists {
+			if dists[i] > dists[far] {
+				far = i
+			}
+		}
+		centroids[c] = clone(points[far])
+		assignments[far] = c
+		dists[far] = 0
+	}
+}
+
+func maxShift(prev, next [][]float32) float64 {
+	var shift float64
+	for c := range prev {
+		if d := float64(vecmath.L2(prev[c], next[c])); d > shift {
+			shift = d
+		}
+	}
+	return shift
+}
+
+func inertia(points [][]float32, assignments []int, centroids [][]float32) float64 {
+	var sum float64
+	for i, p := range points {
+		sum += float64(vecmath.SquaredL2(p, centroids[assignments[i]]))
+	}
+	return sum
+}
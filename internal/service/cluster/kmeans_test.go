@@ -0,0 +1,50 @@
+package cluster
+
+import "testing"
+
+func TestKMeansSeparatesBlobs(t *testing.T) {
+	points := [][]float32{
+		{0, 0}, {0, 1}, {1, 0}, {1, 1},
+		{10, 10}, {10, 11}, {11, 10}, {11, 11},
+	}
+	res := kmeans(points, [][]float32{{0, 0}, {0, 1}}, MetricL2, 50, 1e-6)
+	if len(res.assignments) != len(points) {
+		t.Fatalf("expected %d assignments, got %d", len(points), len(res.assignments))
+	}
+	if len(res.centroids) != 2 {
+		t.Fatalf("expected 2 centroids, got %d", len(res.centroids))
+	}
+	for i := 1; i < 4; i++ {
+		if res.assignments[i] != res.assignments[0] {
+			t.Fatalf("expected point %d in the first blob cluster", i)
+		}
+	}
+	for i := 5; i < 8; i++ {
+		if res.assignments[i] != res.assignments[4] {
+			t.Fatalf("expected point %d in the second blob cluster", i)
+		}
+	}
+	if res.assignments[0] == res.assignments[4] {
+		t.Fatalf("expected blobs in different clusters")
+	}
+	if res.inertia > 4.01 {
+		t.Fatalf("expected inertia 4, got %f", res.inertia)
+	}
+	if !res.converged {
+		t.Fatalf("expected convergence")
+	}
+}
+
+func TestFillEmptyClusters(t *testing.T) {
+	points := [][]float32{{0, 0}, {1, 0}, {9, 9}}
+	assignments := []int{0, 0, 0}
+	dists := []float32{1, 0, 12}
+	centroids := [][]float32{{1, 1}, nil}
+	fillEmptyClusters(points, assignments, dists, centroids)
+	if centroids[1] == nil {
+		t.Fatalf("expected empty cluster to be reseeded")
+	}
+	if assignments[2] != 1 {
+		t.Fatalf("expected farthest point moved to reseeded cluster, got %d", assignments[2])
+	}
+}
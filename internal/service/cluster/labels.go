@@ -0,0 +1,217 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+const (
+	maxLabelLen   = 200
+	maxKeywords   = 50
+	labelKeywords = 10
+	labelWords    = 3
+	// Titles are short and on topic, so their words count more than the
+	// words of the post body.
+	titleWeight = 2
+)
+
+var ErrInvalidLabel = errors.New("invalid cluster label")
+
+// LabelClusters names every cluster after its most distinctive words, scored
+// with class-based TF-IDF: a cluster's documents form one class, and a word
+// ranks high when it is frequent in the class but rare across all classes.
+// Term shares come from the LabelDocs top-scored documents of each cluster,
+// while the rarity of a word is measured over every clustered document.
+// Manual overrides are left in place.
+func (s *ClusterService) LabelClusters(ctx context.Context) (int, error) {
+	if s.clusterRepo == nil || s.docRepo == nil {
+		return 0, fmt.Errorf("cluster service: repo is nil")
+	}
+	perCluster := s.cfg.LabelDocs
+	if perCluster <= 0 {
+		perCluster = 200
+	}
+	docs, err := s.docRepo.ListLabelTexts(ctx, perCluster)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+
+	classes := make(map[int64]map[string]float64)
+	for _, doc := range docs {
+		if doc.ClusterID == nil {
+			continue
+		}
+		terms := classes[*doc.ClusterID]
+		if terms == nil {
+			terms = make(map[string]float64)
+			classes[*doc.ClusterID] = terms
+		}
+		countTerms(terms, doc.Title, doc.Text)
+	}
+
+	corpus, scanned, err := s.corpusTerms(ctx)
+	if err != nil {
+		return 0, err
+	}
+	labels := ctfidf(classes, corpus, labelKeywords)
+	if err := s.clusterRepo.SaveLabels(ctx, labels); err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	s.job.advance(scanned)
+	s.log.Info(ctx, "cluster worker: clusters labelled", logger.FieldAny("clusters", len(labels)), logger.FieldAny("docs", scanned))
+	return len(labels), nil
+}
+
+// corpus holds the term frequencies c-TF-IDF weighs class terms against.
+type corpus struct {
+	// freq is f(t), the weighted count of term t over all clustered documents.
+	freq map[string]float64
+	// avg is A, the average number of weighted words per cluster.
+	avg float64
+}
+
+// corpusTerms counts the terms of every clustered document in one paged
+// pass and returns them with the number of documents read.
+func (s *ClusterService) corpusTerms(ctx context.Context) (corpus, int, error) {
+	freq := make(map[string]float64)
+	clusters := make(map[int64]struct{})
+	var afterID int64
+	var scanned int
+	for {
+		if err := ctx.Err(); err != nil {
+			return corpus{}, 0, err
+		}
+		docs, err := s.docRepo.ListTextsAfter(ctx, afterID, s.batchSize())
+		if err != nil {
+			return corpus{}, 0, fmt.Errorf("cluster service: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+		for _, doc := range docs {
+			if doc.ClusterID != nil {
+				clusters[*doc.ClusterID] = struct{}{}
+			}
+			countTerms(freq, doc.Title, doc.Text)
+		}
+		scanned += len(docs)
+		afterID = docs[len(docs)-1].ID
+	}
+
+	var words float64
+	for _, n := range freq {
+		words += n
+	}
+	c := corpus{freq: freq}
+	if len(clusters) > 0 {
+		c.avg = words / float64(len(clusters))
+	}
+	return c, scanned, nil
+}
+
+func countTerms(terms map[string]float64, title, text string) {
+	for _, w := range tokenize(title) {
+		terms[w] += titleWeight
+	}
+	for _, w := range tokenize(text) {
+		terms[w]++
+	}
+}
+
+// PatchLabel overrides the label or keywords of cluster id. An empty label
+// or keyword list drops the override.
+func (s *ClusterService) PatchLabel(ctx context.Context, id int64, patch cluster.LabelPatch) (cluster.Cluster, error) {
+	if s.clusterRepo == nil {
+		return cluster.Cluster{}, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	if patch.Label == nil && patch.Keywords == nil {
+		return cluster.Cluster{}, fmt.Errorf("%w: nothing to update", ErrInvalidLabel)
+	}
+	if patch.Label != nil {
+		label := strings.TrimSpace(*patch.Label)
+		if len([]rune(label)) > maxLabelLen {
+			return cluster.Cluster{}, fmt.Errorf("%w: label is longer than %d characters", ErrInvalidLabel, maxLabelLen)
+		}
+		patch.Label = &label
+	}
+	if patch.Keywords != nil {
+		if len(*patch.Keywords) > maxKeywords {
+			return cluster.Cluster{}, fmt.Errorf("%w: more than %d keywords", ErrInvalidLabel, maxKeywords)
+		}
+		keywords := make([]string, 0, len(*patch.Keywords))
+		for _, k := range *patch.Keywords {
+			if k = strings.TrimSpace(k); k != "" {
+				keywords = append(keywords, k)
+			}
+		}
+		patch.Keywords = &keywords
+	}
+	if err := s.clusterRepo.PatchLabel(ctx, id, patch); err != nil {
+		return cluster.Cluster{}, err
+	}
+	return s.clusterRepo.Get(ctx, id)
+}
+
+// ctfidf scores term t in class c as tf(t,c) * log(1 + A/f(t)), where tf is
+// the term's share of the class and A and f(t) come from the corpus, and
+// keeps the top terms per class.
+func ctfidf(classes map[int64]map[string]float64, c corpus, top int) []cluster.Label {
+	if len(classes) == 0 || c.avg == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(classes))
+	for id := range classes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	type scored struct {
+		term  string
+		score float64
+	}
+	out := make([]cluster.Label, 0, len(ids))
+	for _, id := range ids {
+		terms := classes[id]
+		var size float64
+		for _, n := range terms {
+			size += n
+		}
+		if size == 0 {
+			continue
+		}
+		ranked := make([]scored, 0, len(terms))
+		for t, n := range terms {
+			// Documents clustered after the corpus pass may carry terms
+			// it has not counted yet.
+			f := max(c.freq[t], n)
+			ranked = append(ranked, scored{t, n / size * math.Log(1+c.avg/f)})
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if ranked[i].score != ranked[j].score {
+				return ranked[i].score > ranked[j].score
+			}
+			return ranked[i].term < ranked[j].term
+		})
+		if len(ranked) > top {
+			ranked = ranked[:top]
+		}
+		keywords := make([]string, len(ranked))
+		for i, r := range ranked {
+			keywords[i] = r.term
+		}
+		out = append(out, cluster.Label{
+			ClusterID: id,
+			Label:     strings.Join(keywords[:min(labelWords, len(keywords))], ", "),
+			Keywords:  keywords,
+		})
+	}
+	return out
+}
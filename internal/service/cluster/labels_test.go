@@ -0,0 +1,50 @@
+package cluster
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize(`Show HN: Rust&#x27;s borrow checker <a href="https://example.com/x">explained</a> in 2024 <p>C++ and C# too`)
+	want := []string{"rust", "borrow", "checker", "explained", "c++", "c#"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTokenizeKeepsShortTerms(t *testing.T) {
+	got := tokenize("Go vs JS: is AI in ML a fit for it?")
+	want := []string{"go", "js", "ai", "ml", "fit"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCTFIDF(t *testing.T) {
+	labels := ctfidf(map[int64]map[string]float64{
+		1: {"rust": 6, "code": 4, "borrow": 2},
+		2: {"python": 6, "code": 4, "pandas": 2},
+	}, corpus{freq: map[string]float64{"rust": 6, "code": 8, "borrow": 2, "python": 6, "pandas": 2}, avg: 12}, 2)
+	if len(labels) != 2 || labels[0].ClusterID != 1 || labels[1].ClusterID != 2 {
+		t.Fatalf("expected labels for clusters 1 and 2 in order, got %+v", labels)
+	}
+	if want := []string{"rust", "borrow"}; !slices.Equal(labels[0].Keywords, want) {
+		t.Fatalf("expected distinctive words %v ahead of the shared one, got %v", want, labels[0].Keywords)
+	}
+	if labels[1].Label != "python, pandas" {
+		t.Fatalf("unexpected label %q", labels[1].Label)
+	}
+}
+
+func TestCTFIDFWeighsTermsAgainstCorpus(t *testing.T) {
+	// "web" looks distinctive in the sampled documents, but the rest of the
+	// corpus uses it everywhere.
+	labels := ctfidf(map[int64]map[string]float64{
+		1: {"web": 3, "wasm": 2},
+		2: {"kernel": 3, "linux": 2},
+	}, corpus{freq: map[string]float64{"web": 400, "wasm": 2, "kernel": 3, "linux": 2}, avg: 200}, 1)
+	if len(labels) != 2 || labels[0].Label != "wasm" {
+		t.Fatalf("expected the corpus-rare term to label cluster 1, got %+v", labels)
+	}
+}
@@ -0,0 +1,41 @@
+package cluster
+
+import (
+	"context"
+
+	"NeoBIT/internal/db"
+)
+
+// lockWrites takes the cross-instance cluster write lock: shared for a batch,
+// exclusive when the cluster set is replaced. Without a locker it only
+// relies on the in-process mutexes.
+func (s *ClusterService) lockWrites(ctx context.Context, exclusive bool) (func(), error) {
+	if s.locker == nil {
+		return func() {}, nil
+	}
+	if exclusive {
+		return s.locker.Lock(ctx, db.LockClusterWrite)
+	}
+	return s.locker.LockShared(ctx, db.LockClusterWrite)
+}
+
+func (s *ClusterService) tryLockRecluster(ctx context.Context) (func(), bool, error) {
+	if s.locker == nil {
+		return func() {}, true, nil
+	}
+	return s.locker.TryLock(ctx, db.LockRecluster)
+}
+
+func (s *ClusterService) tryLockDuplicates(ctx context.Context) (func(), bool, error) {
+	if s.locker == nil {
+		return func() {}, true, nil
+	}
+	return s.locker.TryLock(ctx, db.LockDuplicates)
+}
+
+func (s *ClusterService) tryLockMap(ctx context.Context) (func(), bool, error) {
+	if s.locker == nil {
+		return func() {}, true, nil
+	}
+	return s.locker.TryLock(ctx, db.LockMap)
+}
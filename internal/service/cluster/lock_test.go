@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+type recordingLocker struct {
+	calls []string
+	held  int
+}
+
+func (l *recordingLocker) lock(mode string) func() {
+	l.calls = append(l.calls, mode)
+	l.held++
+	return func() { l.held-- }
+}
+
+func (l *recordingLocker) Lock(ctx context.Context, key int64) (func(), error) {
+	return l.lock("exclusive"), nil
+}
+
+func (l *recordingLocker) LockShared(ctx context.Context, key int64) (func(), error) {
+	return l.lock("shared"), nil
+}
+
+func (l *recordingLocker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	return l.lock("try"), true, nil
+}
+
+func TestLockBatch(t *testing.T) {
+	cfg := config.DefaultClusterConfig()
+	clusters := &memClusterRepo{}
+	locker := &recordingLocker{}
+	svc, err := NewService(clusters, &fakeDocRepo{}, nil, locker, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	kind, unlock, err := svc.lockBatch(context.Background())
+	if err != nil {
+		t.Fatalf("lockBatch: %v", err)
+	}
+	if kind != cluster.RunKindBatch {
+		t.Fatalf("expected first fit, got %s", kind)
+	}
+	if want := []string{"shared", "exclusive"}; !slices.Equal(locker.calls, want) || locker.held != 1 {
+		t.Fatalf("expected %v with one lock held, got %v (%d held)", want, locker.calls, locker.held)
+	}
+	unlock()
+
+	clusters.clusters = []cluster.Cluster{{ID: 1}}
+	locker.calls = nil
+	kind, unlock, err = svc.lockBatch(context.Background())
+	if err != nil {
+		t.Fatalf("lockBatch: %v", err)
+	}
+	unlock()
+	if kind != cluster.RunKindIncremental || !slices.Equal(locker.calls, []string{"shared"}) {
+		t.Fatalf("expected incremental under a shared lock, got %s %v", kind, locker.calls)
+	}
+	if locker.held != 0 {
+		t.Fatalf("expected every lock released, %d held", locker.held)
+	}
+}
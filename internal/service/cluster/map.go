@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+)
+
+// ProjectMap lays the corpus out in 2D for the cluster map: PCA onto the two
+// main axes is fitted on a corpus sample and applied to every document, and
+// the documents are then ranked for proportional sampling. New documents stay
+// off the map until the next run. It returns the number of documents placed.
+func (s *ClusterService) ProjectMap(ctx context.Context) (int, error) {
+	if s.docRepo == nil {
+		return 0, fmt.Errorf("cluster service: doc repo is nil")
+	}
+	unlock, ok, err := s.tryLockMap(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: lock map: %w", err)
+	}
+	if !ok {
+		s.log.Info(ctx, "cluster worker: map projection running on another instance")
+		return 0, nil
+	}
+	defer unlock()
+
+	sampleSize := s.cfg.MapSample
+	if sampleSize <= 0 {
+		sampleSize = 20000
+	}
+	sample, err := s.corpusSampler()(ctx, sampleSize)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: sample corpus: %w", err)
+	}
+	if len(sample) < 2 {
+		return 0, nil
+	}
+	proj, err := fitPCA(s.metric.prepare(sample), 2, 0)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	if proj.dims() < 2 {
+		return 0, fmt.Errorf("cluster service: embeddings have fewer than 2 dimensions")
+	}
+
+	placed := 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return placed, err
+		}
+		docs, err := s.docRepo.ListEmbeddingsAfter(ctx, afterID, s.batchSize())
+		if err != nil {
+			return placed, fmt.Errorf("cluster service: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+		afterID = docs[len(docs)-1].ID
+
+		ids := make([]int64, len(docs))
+		points := make([][]float32, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.ID
+			points[i] = doc.Embedding
+		}
+		coords := proj.applyAll(s.metric.prepare(points))
+		xs := make([]float32, len(coords))
+		ys := make([]float32, len(coords))
+		for i, c := range coords {
+			xs[i], ys[i] = c[0], c[1]
+		}
+		if err := s.docRepo.SaveMapCoordinates(ctx, ids, xs, ys); err != nil {
+			return placed, fmt.Errorf("cluster service: %w", err)
+		}
+		placed += len(docs)
+		s.job.advance(len(docs))
+	}
+	// Ranking once here keeps the unfiltered map read to an index scan.
+	if _, err := s.docRepo.RankMapPoints(ctx); err != nil {
+		return placed, fmt.Errorf("cluster service: %w", err)
+	}
+
+	s.log.Info(ctx, "cluster worker: map projected",
+		logger.FieldAny("docs", placed),
+		logger.FieldAny("explained_variance", proj.explained),
+	)
+	return placed, nil
+}
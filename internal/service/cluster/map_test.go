@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/document"
+)
+
+type mapDocRepo struct {
+	duplicateDocRepo
+	coords map[int64][2]float32
+	ranked int
+}
+
+func (m *mapDocRepo) Count(ctx context.Context) (int64, error) {
+	return int64(len(m.docs)), nil
+}
+
+func (m *mapDocRepo) SampleEmbeddings(ctx context.Context, fraction float64, limit int) ([][]float32, error) {
+	var out [][]float32
+	for _, doc := range m.docs[:min(limit, len(m.docs))] {
+		out = append(out, doc.Embedding)
+	}
+	return out, nil
+}
+
+func (m *mapDocRepo) SaveMapCoordinates(ctx context.Context, ids []int64, xs, ys []float32) error {
+	for i, id := range ids {
+		m.coords[id] = [2]float32{xs[i], ys[i]}
+	}
+	return nil
+}
+
+func (m *mapDocRepo) RankMapPoints(ctx context.Context) (int64, error) {
+	m.ranked++
+	return int64(len(m.coords)), nil
+}
+
+func TestProjectMapPlacesEveryDocument(t *testing.T) {
+	// Documents spread along the first axis, less along the second and
+	// barely along the third.
+	docs := &mapDocRepo{coords: map[int64][2]float32{}}
+	for i := 0; i < 50; i++ {
+		s := float32(i - 25)
+		docs.docs = append(docs.docs, document.Document{
+			ID:        int64(i + 1),
+			Embedding: []float32{s, 0.1 * s * float32(i%2*2-1), 0.001 * float32(i%3)},
+		})
+	}
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "l2"
+	cfg.BatchSize = 7
+	svc, err := NewService(&fakeClusterRepo{}, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n, err := svc.ProjectMap(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 50 || len(docs.coords) != 50 {
+		t.Fatalf("expected 50 documents placed, got %d (%d saved)", n, len(docs.coords))
+	}
+	if docs.ranked != 1 {
+		t.Fatalf("expected map points ranked once after placing, got %d", docs.ranked)
+	}
+	// The map is centred and its axes have an arbitrary sign, but x must
+	// follow the first coordinate and y the second.
+	first, last := docs.coords[1], docs.coords[50]
+	sign := float32(math.Copysign(1, float64(last[0]-first[0])))
+	for i := int64(2); i <= 50; i++ {
+		if sign*docs.coords[i][0] <= sign*docs.coords[i-1][0] {
+			t.Fatalf("expected x to grow along the main axis, got %v then %v", docs.coords[i-1], docs.coords[i])
+		}
+	}
+	for _, doc := range docs.docs {
+		got := docs.coords[doc.ID]
+		if math.Abs(math.Abs(float64(got[1]))-math.Abs(float64(doc.Embedding[1]))) > 0.1 {
+			t.Fatalf("doc %d: expected y along the second axis (%v), got %v", doc.ID, doc.Embedding, got)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/metrics"
+	"NeoBIT/internal/models/cluster"
+)
+
+const (
+	defaultSplitK = 2
+	maxSplitK     = 20
+)
+
+var (
+	ErrInvalidMerge       = errors.New("invalid cluster merge")
+	ErrInvalidSplit       = errors.New("invalid cluster split")
+	ErrClusterNotEditable = errors.New("cluster cannot be merged or split")
+)
+
+// Merge moves the documents of cluster sourceID into targetID, recomputes the
+// target's centroid and retires the source. The merge is recorded as a run,
+// so rolling back to an earlier run undoes it. The last live sub-cluster of a
+// parent cannot be merged away: the parent would turn back into a leaf with
+// no documents and the centroid of the split, so the split has to be rolled
+// back instead.
+func (s *ClusterService) Merge(ctx context.Context, sourceID, targetID int64) (cluster.Run, error) {
+	if s.clusterRepo == nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	if sourceID == targetID {
+		return cluster.Run{}, fmt.Errorf("%w: cluster %d cannot be merged into itself", ErrInvalidMerge, sourceID)
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	unlock, err := s.lockWrites(ctx, true)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: lock merge: %w", err)
+	}
+	defer unlock()
+
+	source, err := s.editableCluster(ctx, sourceID)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	if source.ParentID != nil {
+		siblings, err := s.clusterRepo.ListChildren(ctx, *source.ParentID, 2, 0)
+		if err != nil {
+			return cluster.Run{}, fmt.Errorf("cluster service: list children: %w", err)
+		}
+		if len(siblings) < 2 {
+			return cluster.Run{}, fmt.Errorf("%w: cluster %d is the last sub-cluster of %d; roll back the split instead", ErrInvalidMerge, sourceID, *source.ParentID)
+		}
+	}
+	target, err := s.editableCluster(ctx, targetID)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+
+	runID, err := s.startRun(ctx, cluster.RunKindMerge, 0, func(p *runParams) {
+		p.SourceCluster = sourceID
+		p.TargetCluster = targetID
+	})
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: start merge run: %w", err)
+	}
+
+	var moved int64
+	var updates []cluster.CentroidUpdate
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		var err error
+		moved, err = s.clusterRepo.MergeClusters(ctx, runID, sourceID, targetID)
+		if err != nil {
+			return err
+		}
+		if source.Size+target.Size == 0 {
+			return nil
+		}
+		stat, err := s.clusterRepo.CentroidStat(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		updates = s.centroidUpdates([]cluster.CentroidStat{stat})
+		return s.clusterRepo.UpdateCentroids(ctx, updates)
+	})
+	s.finishRun(ctx, runID, runSummary{K: 1, Docs: int(moved)}, err)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	for _, u := range updates {
+		metrics.SetClusterDrift(u.ClusterID, u.Drift)
+	}
+	s.log.Info(ctx, "cluster service: clusters merged",
+		logger.FieldAny("run_id", runID),
+		logger.FieldAny("source", sourceID),
+		logger.FieldAny("target", targetID),
+		logger.FieldAny("docs", moved),
+	)
+	s.refreshMetrics(ctx)
+	return s.clusterRepo.GetRun(ctx, runID)
+}
+
+// Split runs k-means with k clusters over the documents of cluster id and
+// moves them into new sub-clusters of it. A k of 0 means defaultSplitK.
+func (s *ClusterService) Split(ctx context.Context, id int64, k int) (cluster.Run, error) {
+	if s.clusterRepo == nil || s.docRepo == nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: repo is nil")
+	}
+	if k == 0 {
+		k = defaultSplitK
+	}
+	if k < 2 || k > maxSplitK {
+		return cluster.Run{}, fmt.Errorf("%w: k must be between 2 and %d", ErrInvalidSplit, maxSplitK)
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	unlock, err := s.lockWrites(ctx, true)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: lock split: %w", err)
+	}
+	defer unlock()
+
+	parent, err := s.editableCluster(ctx, id)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	docs, err := s.docRepo.ListClusterEmbeddings(ctx, id)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: %w", err)
+	}
+	points := make([][]float32, 0, len(docs))
+	ids := make([]int64, 0, len(docs))
+	for _, doc := range docs {
+		if len(doc.Embedding) == 0 {
+			continue
+		}
+		points = append(points, doc.Embedding)
+		ids = append(ids, doc.ID)
+	}
+	if len(points) < k {
+		return cluster.Run{}, fmt.Errorf("%w: cluster %d has %d documents, fewer than k=%d", ErrInvalidSplit, id, len(points), k)
+	}
+
+	cfg := s.cfg
+	cfg.Algorithm = "kmeans"
+	cfg.K = k
+	cfg.AutoK = false
+	km, err := s.withConfig(cfg)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: %w", err)
+	}
+
+	seed := resolveSeed(s.cfg.Seed)
+	runID, err := km.startRun(ctx, cluster.RunKindSplit, seed, func(p *runParams) { p.SourceCluster = id })
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: start split run: %w", err)
+	}
+	summary, err := km.splitCluster(ctx, runID, seed, parent, ids, s.metric.prepare(points))
+	km.finishRun(ctx, runID, summary, err)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	s.refreshMetrics(ctx)
+	return s.clusterRepo.GetRun(ctx, runID)
+}
+
+func (s *ClusterService) splitCluster(ctx context.Context, runID, seed int64, parent cluster.Cluster, ids []int64, points [][]float32) (runSummary, error) {
+	_, res, quality, err := s.fit(ctx, s.options(seed, len(points)), points)
+	if err != nil {
+		return runSummary{}, err
+	}
+	summary := runSummary{K: len(res.Centroids), Diagnostics: res.Diagnostics}
+
+	clusterIDs := make([]int64, len(res.Centroids))
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		for i, centroid := range res.Centroids {
+			childID, err := s.clusterRepo.Create(ctx, cluster.Cluster{
+				Algorithm:    s.cfg.Algorithm,
+				Metric:       string(s.metric),
+				K:            summary.K,
+				RunID:        &runID,
+				GenerationID: parent.GenerationID,
+				ParentID:     &parent.ID,
+				Level:        parent.Level + 1,
+				Centroid:     centroid,
+				Cohesion:     quality.Cohesion[i],
+			})
+			if err != nil {
+				return fmt.Errorf("create cluster: %w", err)
+			}
+			clusterIDs[i] = childID
+		}
+
+		buckets := make(map[int64][]int64, len(clusterIDs))
+		for i, clusterIdx := range res.Assignments {
+			buckets[clusterIDs[clusterIdx]] = append(buckets[clusterIDs[clusterIdx]], ids[i])
+		}
+		for clusterID, docIDs := range buckets {
+			if err := s.assign(ctx, runID, docIDs, clusterID); err != nil {
+				return fmt.Errorf("update cluster ids: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+	summary.Docs = len(points)
+	s.log.Info(ctx, "cluster service: cluster split",
+		logger.FieldAny("run_id", runID),
+		logger.FieldAny("cluster", parent.ID),
+		logger.FieldAny("clusters", clusterIDs),
+		logger.FieldAny("docs", len(points)),
+	)
+	return summary, nil
+}
+
+// editableCluster loads a cluster that documents can be moved into or out
+// of: a live leaf of the active generation clustered with the service's
+// metric.
+func (s *ClusterService) editableCluster(ctx context.Context, id int64) (cluster.Cluster, error) {
+	c, err := s.clusterRepo.Get(ctx, id)
+	if err != nil {
+		return cluster.Cluster{}, err
+	}
+	if !c.Active {
+		return cluster.Cluster{}, fmt.Errorf("%w: cluster %d is merged or not in the active generation", ErrClusterNotEditable, id)
+	}
+	if c.Metric != string(s.metric) {
+		return cluster.Cluster{}, fmt.Errorf("%w: cluster %d uses metric %s, not %s", ErrClusterNotEditable, id, c.Metric, s.metric)
+	}
+	children, err := s.clusterRepo.ListChildren(ctx, id, 1, 0)
+	if err != nil {
+		return cluster.Cluster{}, fmt.Errorf("cluster service: list children: %w", err)
+	}
+	if len(children) > 0 {
+		return cluster.Cluster{}, fmt.Errorf("%w: cluster %d has sub-clusters", ErrClusterNotEditable, id)
+	}
+	return c, nil
+}
@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/models/document"
+)
+
+type editRepo struct {
+	runRecordingRepo
+	merged  [][3]int64
+	stat    cluster.CentroidStat
+	updates []cluster.CentroidUpdate
+}
+
+func (e *editRepo) Get(ctx context.Context, id int64) (cluster.Cluster, error) {
+	return e.clusters[id-1], nil
+}
+
+func (e *editRepo) ListChildren(ctx context.Context, parentID int64, limit, offset int) ([]cluster.Cluster, error) {
+	var out []cluster.Cluster
+	for _, c := range e.clusters {
+		if c.ParentID != nil && *c.ParentID == parentID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (e *editRepo) MergeClusters(ctx context.Context, runID, sourceID, targetID int64) (int64, error) {
+	e.merged = append(e.merged, [3]int64{runID, sourceID, targetID})
+	return e.clusters[sourceID-1].Size, nil
+}
+
+func (e *editRepo) CentroidStat(ctx context.Context, clusterID int64) (cluster.CentroidStat, error) {
+	return e.stat, nil
+}
+
+func (e *editRepo) UpdateCentroids(ctx context.Context, updates []cluster.CentroidUpdate) error {
+	e.updates = updates
+	return nil
+}
+
+type clusterDocRepo struct {
+	recordingDocRepo
+	docs []document.Document
+}
+
+func (c *clusterDocRepo) ListClusterEmbeddings(ctx context.Context, clusterID int64) ([]document.Document, error) {
+	return c.docs, nil
+}
+
+func newEditService(t *testing.T, repo *editRepo, docs DocumentRepository) *ClusterService {
+	t.Helper()
+	repo.assignments = map[int64]map[int64]*int64{}
+	svc, err := NewService(repo, docs, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return svc
+}
+
+func TestMergeRecordsRunAndRecomputesCentroid(t *testing.T) {
+	repo := &editRepo{
+		runRecordingRepo: runRecordingRepo{memClusterRepo: memClusterRepo{clusters: []cluster.Cluster{
+			{ID: 1, Metric: "cosine", Active: true, Centroid: []float32{1, 0}, Size: 2},
+			{ID: 2, Metric: "cosine", Active: true, Centroid: []float32{0, 1}, Size: 3},
+		}}},
+		stat: cluster.CentroidStat{ClusterID: 2, Centroid: []float32{0, 1}, Mean: []float32{1, 1}, Size: 5},
+	}
+	svc := newEditService(t, repo, &fakeDocRepo{})
+
+	if _, err := svc.Merge(context.Background(), 1, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.runs) != 1 || repo.runs[0].Kind != cluster.RunKindMerge {
+		t.Fatalf("expected one merge run, got %+v", repo.runs)
+	}
+	if len(repo.merged) != 1 || repo.merged[0] != [3]int64{1, 1, 2} {
+		t.Fatalf("expected cluster 1 merged into 2 by run 1, got %v", repo.merged)
+	}
+	if got := repo.finished[0]; got.Status != cluster.RunStatusSucceeded || got.DocsProcessed != 2 {
+		t.Fatalf("expected a succeeded run moving 2 docs, got %+v", got)
+	}
+	if len(repo.updates) != 1 || repo.updates[0].ClusterID != 2 || repo.updates[0].Size != 5 {
+		t.Fatalf("expected the target centroid to be recomputed, got %+v", repo.updates)
+	}
+}
+
+func TestMergeRejectsInvalidClusters(t *testing.T) {
+	parentID := int64(1)
+	repo := &editRepo{runRecordingRepo: runRecordingRepo{memClusterRepo: memClusterRepo{clusters: []cluster.Cluster{
+		{ID: 1, Metric: "cosine", Active: true},
+		{ID: 2, Metric: "cosine", Active: true, ParentID: &parentID},
+		{ID: 3, Metric: "cosine", Active: false},
+		{ID: 4, Metric: "l2", Active: true},
+		{ID: 5, Metric: "cosine", Active: true},
+	}}}}
+	svc := newEditService(t, repo, &fakeDocRepo{})
+
+	cases := []struct {
+		source, target int64
+		want           error
+	}{
+		{2, 2, ErrInvalidMerge},
+		{1, 2, ErrClusterNotEditable},
+		{3, 2, ErrClusterNotEditable},
+		{4, 2, ErrClusterNotEditable},
+		{2, 5, ErrInvalidMerge},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Merge(context.Background(), tc.source, tc.target); !errors.Is(err, tc.want) {
+			t.Fatalf("merge %d into %d: expected %v, got %v", tc.source, tc.target, tc.want, err)
+		}
+	}
+	if len(repo.runs) != 0 {
+		t.Fatalf("expected rejected merges not to start runs, got %+v", repo.runs)
+	}
+}
+
+func TestSplitCreatesSubClusters(t *testing.T) {
+	generationID := int64(7)
+	repo := &editRepo{runRecordingRepo: runRecordingRepo{memClusterRepo: memClusterRepo{clusters: []cluster.Cluster{
+		{ID: 1, Metric: "cosine", Active: true, GenerationID: &generationID, Level: 1, Size: 4},
+	}}}}
+	docs := &clusterDocRepo{
+		recordingDocRepo: recordingDocRepo{assigned: map[int64]int64{}},
+		docs: []document.Document{
+			{ID: 10, Embedding: []float32{1, 0}},
+			{ID: 11, Embedding: []float32{1, 0.1}},
+			{ID: 12, Embedding: []float32{0, 1}},
+			{ID: 13, Embedding: []float32{0.1, 1}},
+		},
+	}
+	svc := newEditService(t, repo, docs)
+
+	for _, k := range []int{1, maxSplitK + 1, 5} {
+		if _, err := svc.Split(context.Background(), 1, k); !errors.Is(err, ErrInvalidSplit) {
+			t.Fatalf("k=%d: expected ErrInvalidSplit, got %v", k, err)
+		}
+	}
+
+	if _, err := svc.Split(context.Background(), 1, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.runs) != 1 || repo.runs[0].Kind != cluster.RunKindSplit || repo.runs[0].Algorithm != "kmeans" {
+		t.Fatalf("expected one kmeans split run, got %+v", repo.runs)
+	}
+	children := repo.clusters[1:]
+	if len(children) != 2 {
+		t.Fatalf("expected 2 sub-clusters, got %d", len(children))
+	}
+	for _, c := range children {
+		if c.ParentID == nil || *c.ParentID != 1 || c.Level != 2 || c.GenerationID == nil || *c.GenerationID != generationID {
+			t.Fatalf("expected a level 2 child of cluster 1 in generation 7, got %+v", c)
+		}
+	}
+	if docs.assigned[10] != docs.assigned[11] || docs.assigned[12] != docs.assigned[13] || docs.assigned[10] == docs.assigned[12] {
+		t.Fatalf("expected the two directions to be split apart, got %v", docs.assigned)
+	}
+	if len(repo.assignments[1]) != 4 {
+		t.Fatalf("expected 4 assignments recorded for the split run, got %v", repo.assignments[1])
+	}
+}
@@ -0,0 +1,71 @@
+package cluster
+
+import (
+	"fmt"
+
+	"NeoBIT/internal/vecmath"
+)
+
+type Metric string
+
+const (
+	MetricCosine       Metric = "cosine"
+	MetricL2           Metric = "l2"
+	MetricInnerProduct Metric = "inner_product"
+)
+
+func ParseMetric(name string) (Metric, error) {
+	switch m := Metric(name); m {
+	case MetricCosine, MetricL2, MetricInnerProduct:
+		return m, nil
+	case "":
+		return MetricCosine, nil
+	default:
+		return "", fmt.Errorf("cluster: unsupported metric %q", name)
+	}
+}
+
+// Distance follows pgvector semantics: cosine distance is 1 - cos(a, b) and
+// inner product distance is the negated dot product.
+func (m Metric) Distance(a, b []float32) float32 {
+	switch m {
+	case MetricCosine:
+		return vecmath.CosineDistance(a, b, vecmath.Norm(a), vecmath.Norm(b))
+	case MetricInnerProduct:
+		return -vecmath.Dot(a, b)
+	default:
+		return vecmath.L2(a, b)
+	}
+}
+
+// spherical reports whether points and centroids live on the unit sphere,
+// i.e. the clusterer runs spherical k-means.
+func (m Metric) spherical() bool {
+	return m == MetricCosine
+}
+
+func (m Metric) prepare(points [][]float32) [][]float32 {
+	if !m.spherical() {
+		return points
+	}
+	out := make([][]float32, len(points))
+	for i, p := range points {
+		out[i] = normalize(clone(p))
+	}
+	return out
+}
+
+func (m Metric) project(centroid []float32) []float32 {
+	if m.spherical() {
+		return normalize(centroid)
+	}
+	return centroid
+}
+
+func norm(v []float32) float32 {
+	return vecmath.Norm(v)
+}
+
+func normalize(v []float32) []float32 {
+	return vecmath.Normalize(v)
+}
@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseMetric(t *testing.T) {
+	if m, err := ParseMetric(""); err != nil || m != MetricCosine {
+		t.Fatalf("expected cosine by default, got %q %v", m, err)
+	}
+	if _, err := ParseMetric("manhattan"); err == nil {
+		t.Fatalf("expected error for unsupported metric")
+	}
+}
+
+func TestMetricDistance(t *testing.T) {
+	a := []float32{1, 0}
+	b := []float32{0, 2}
+	if d := MetricCosine.Distance(a, b); math.Abs(float64(d)-1) > 1e-6 {
+		t.Fatalf("expected cosine distance 1, got %f", d)
+	}
+	if d := MetricL2.Distance(a, b); math.Abs(float64(d)-math.Sqrt(5)) > 1e-6 {
+		t.Fatalf("expected l2 distance sqrt(5), got %f", d)
+	}
+	if d := MetricInnerProduct.Distance([]float32{1, 2}, []float32{3, 4}); d != -11 {
+		t.Fatalf("expected negative inner product -11, got %f", d)
+	}
+}
+
+func TestSphericalKMeansGroupsByDirection(t *testing.T) {
+	points := MetricCosine.prepare([][]float32{
+		{1, 0.1}, {10, 0.5}, {100, 3},
+		{0.1, 1}, {0.3, 20}, {2, 90},
+	})
+	res := kmeans(points, [][]float32{points[0], points[3]}, MetricCosine, 20, 1e-6)
+	if res.assignments[1] != res.assignments[0] || res.assignments[2] != res.assignments[0] {
+		t.Fatalf("expected x-axis points together, got %v", res.assignments)
+	}
+	if res.assignments[4] != res.assignments[3] || res.assignments[5] != res.assignments[3] {
+		t.Fatalf("expected y-axis points together, got %v", res.assignments)
+	}
+	for _, c := range res.centroids {
+		if n := norm(c); math.Abs(float64(n)-1) > 1e-5 {
+			t.Fatalf("expected unit centroid, got norm %f", n)
+		}
+	}
+}
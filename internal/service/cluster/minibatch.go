@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type sampleFunc func(ctx context.Context, n int) ([][]float32, error)
+
+type miniBatchClusterer struct {
+	centroidModel
+	opts Options
+}
+
+func newMiniBatchClusterer(opts Options) Clusterer {
+	return &miniBatchClusterer{centroidModel: centroidModel{metric: opts.Metric}, opts: opts}
+}
+
+func (c *miniBatchClusterer) Fit(ctx context.Context, points [][]float32) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, fmt.Errorf("minibatch kmeans: no points")
+	}
+	if c.opts.Sample == nil {
+		return Result{}, fmt.Errorf("minibatch kmeans: sampler is not configured")
+	}
+	k := clampK(c.opts.K, len(points))
+	init := initCentroids(points, k, c.opts.Config.Init, c.opts.Rand)
+	res, err := miniBatchKMeans(ctx, c.opts.Sample, init, c.opts.Metric, c.opts.Config.MiniBatchSize, c.opts.Config.MiniBatchIters, c.opts.Config.Tolerance)
+	if err != nil {
+		return Result{}, err
+	}
+	centroids := res.centroids
+	c.centroids = centroids
+
+	assignments, err := c.Predict(points)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Assignments: assignments,
+		Centroids:   centroids,
+		Diagnostics: Diagnostics{
+			Iterations: res.iterations,
+			Converged:  res.converged,
+			Inertia:    inertia(points, assignments, centroids),
+		},
+	}, nil
+}
+
+type miniBatchResult struct {
+	centroids  [][]float32
+	iterations int
+	converged  bool
+}
+
+// miniBatchKMeans refines centroids over random samples drawn from the whole
+// corpus using per-centroid learning rates (Sculley, 2010). It stops early,
+// converged, once no centroid moves more than tolerance in an iteration, or
+// when the sampler runs dry.
+func miniBatchKMeans(ctx context.Context, sample sampleFunc, centroids [][]float32, metric Metric, batchSize, iterations int, tolerance float64) (miniBatchResult, error) {
+	if batchSize <= 0 {
+		batchSize = 256
+	}
+	if iterations <= 0 {
+		iterations = 50
+	}
+
+	res := miniBatchResult{}
+	counts := make([]int, len(centroids))
+	nearestIdx := make([]int, 0, batchSize)
+	prev := make([][]float32, len(centroids))
+	for iter := 1; iter <= iterations; iter++ {
+		if err := ctx.Err(); err != nil {
+			return miniBatchResult{}, err
+		}
+
+		batch, err := sample(ctx, batchSize)
+		if err != nil {
+			return miniBatchResult{}, fmt.Errorf("minibatch kmeans: sample: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		batch = metric.prepare(batch)
+		res.iterations = iter
+
+		nearestIdx = slices.Grow(nearestIdx[:0], len(batch))[:len(batch)]
+		newCentroidSet(centroids, metric).assign(batch, nearestIdx, nil)
+
+		for c := range centroids {
+			prev[c] = append(prev[c][:0], centroids[c]...)
+		}
+		for i, p := range batch {
+			c := nearestIdx[i]
+			counts[c]++
+			eta := 1 / float32(counts[c])
+			centroid := centroids[c]
+			for j := range centroid {
+				centroid[j] = (1-eta)*centroid[j] + eta*p[j]
+			}
+			centroids[c] = metric.project(centroid)
+		}
+		if maxShift(prev, centroids) <= tolerance {
+			res.converged = true
+			break
+		}
+	}
+	res.centroids = centroids
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMiniBatchKMeansMovesCentroidsTowardsSamples(t *testing.T) {
+	corpus := [][]float32{{0, 0}, {0, 1}, {1, 0}, {10, 10}, {10, 11}, {11, 10}}
+	sample := func(ctx context.Context, n int) ([][]float32, error) {
+		return corpus, nil
+	}
+
+	res, err := miniBatchKMeans(context.Background(), sample, [][]float32{{2, 2}, {8, 8}}, MetricL2, 6, 20, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	centroids := res.centroids
+	if d := distance(centroids[0], []float32{1.0 / 3, 1.0 / 3}); d > 0.5 {
+		t.Fatalf("expected first centroid near low blob, got %v", centroids[0])
+	}
+	if d := distance(centroids[1], []float32{31.0 / 3, 31.0 / 3}); d > 0.5 {
+		t.Fatalf("expected second centroid near high blob, got %v", centroids[1])
+	}
+}
+
+func TestMiniBatchKMeansStopsOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sample := func(ctx context.Context, n int) ([][]float32, error) {
+		return [][]float32{{0, 0}}, nil
+	}
+	if _, err := miniBatchKMeans(ctx, sample, [][]float32{{0, 0}}, MetricL2, 1, 5, 0); err == nil {
+		t.Fatalf("expected context error")
+	}
+}
+
+func TestMiniBatchKMeansReportsIterationsRun(t *testing.T) {
+	// The same batch every time leaves the centroids in place after the
+	// second iteration, so the loop stops well before 50.
+	sample := func(ctx context.Context, n int) ([][]float32, error) {
+		return [][]float32{{0, 0}, {10, 10}}, nil
+	}
+	res, err := miniBatchKMeans(context.Background(), sample, [][]float32{{1, 1}, {9, 9}}, MetricL2, 2, 50, 1e-6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.converged || res.iterations >= 50 {
+		t.Fatalf("expected early convergence, got %d iterations, converged=%v", res.iterations, res.converged)
+	}
+
+	calls := 0
+	drained := func(ctx context.Context, n int) ([][]float32, error) {
+		calls++
+		if calls > 3 {
+			return nil, nil
+		}
+		return [][]float32{{float32(calls), 0}}, nil
+	}
+	res, err = miniBatchKMeans(context.Background(), drained, [][]float32{{0, 0}}, MetricL2, 1, 50, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.converged || res.iterations != 3 {
+		t.Fatalf("expected 3 iterations without convergence, got %d, converged=%v", res.iterations, res.converged)
+	}
+}
@@ -1,12 +1,39 @@
 package cluster
 
 import (
-	"math"
+	"context"
+	"fmt"
 	"math/rand"
-	"time"
+
+	"NeoBIT/internal/vecmath"
 )
 
-func assignOnePass(points [][]float32, k int) ([]int, [][]float32) {
+type onePassClusterer struct {
+	centroidModel
+	opts Options
+}
+
+func newOnePassClusterer(opts Options) Clusterer {
+	return &onePassClusterer{centroidModel: centroidModel{metric: opts.Metric}, opts: opts}
+}
+
+func (c *onePassClusterer) Fit(ctx context.Context, points [][]float32) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, fmt.Errorf("simple: no points")
+	}
+	assignments, centroids := assignOnePass(points, c.opts.K, c.opts.Metric, c.opts.Rand)
+	c.centroids = centroids
+	return Result{
+		Assignments: assignments,
+		Centroids:   centroids,
+		Diagnostics: Diagnostics{
+			Iterations: 1,
+			Inertia:    inertia(points, assignments, centroids),
+		},
+	}, nil
+}
+
+func assignOnePass(points [][]float32, k int, metric Metric, rnd *rand.Rand) ([]int, [][]float32) {
 	if k <= 0 {
 		k = 1
 	}
@@ -14,10 +41,16 @@ func assignOnePass(points [][]float32, k int) ([]int, [][]float32) {
 		k = len(points)
 	}
 
+	centroids := kmeansPlusPlus(points, nil, k, rnd)
+	assignments := make([]int, len(points))
+	newCentroidSet(centroids, metric).assign(points, assignments, nil)
+
+	return assignments, centroids
+}
+
+func randomCentroids(points [][]float32, k int, rnd *rand.Rand) [][]float32 {
 	centroids := make([][]float32, 0, k)
 	chosen := make(map[int]struct{}, k)
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	for len(centroids) < k {
 		idx := rnd.Intn(len(points))
 		if _, ok := chosen[idx]; ok {
@@ -26,31 +59,11 @@ func assignOnePass(points [][]float32, k int) ([]int, [][]float32) {
 		chosen[idx] = struct{}{}
 		centroids = append(centroids, clone(points[idx]))
 	}
-
-	assignments := make([]int, len(points))
-	for i, p := range points {
-		best := 0
-		bestDist := distance(p, centroids[0])
-		for c := 1; c < k; c++ {
-			d := distance(p, centroids[c])
-			if d < bestDist {
-				bestDist = d
-				best = c
-			}
-		}
-		assignments[i] = best
-	}
-
-	return assignments, centroids
+	return centroids
 }
 
 func distance(a, b []float32) float32 {
-	var sum float32
-	for i := range a {
-		d := a[i] - b[i]
-		sum += d * d
-	}
-	return float32(math.Sqrt(float64(sum)))
+	return vecmath.L2(a, b)
 }
 
 func clone(v []float32) []float32 {
@@ -9,7 +9,7 @@ func TestAssignOnePass(t *testing.T) {
 		{10, 10},
 		{11, 11},
 	}
-	assignments, centroids := assignOnePass(points, 2)
+	assignments, centroids := assignOnePass(points, 2, MetricL2, newRand(42))
 	if len(assignments) != len(points) {
 		t.Fatalf("expected %d assignments, got %d", len(points), len(assignments))
 	}
@@ -19,4 +19,10 @@ func TestAssignOnePass(t *testing.T) {
 	if len(centroids[0]) != len(points[0]) {
 		t.Fatalf("centroid dimension mismatch")
 	}
+	if assignments[0] != assignments[1] || assignments[2] != assignments[3] {
+		t.Fatalf("expected close points to share a cluster, got %v", assignments)
+	}
+	if assignments[0] == assignments[2] {
+		t.Fatalf("expected distant points in different clusters, got %v", assignments)
+	}
 }
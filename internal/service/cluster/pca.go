@@ -0,0 +1,307 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/vecmath"
+)
+
+// projection is a fitted PCA: points are centred on mean and projected onto
+// components, the principal axes in order of decreasing variance.
+type projection struct {
+	mean       []float32
+	components [][]float32
+	// offsets[i] is components[i]·mean, so that projecting does not need a
+	// centred copy of the point.
+	offsets   []float32
+	explained float64
+}
+
+func newProjection(mean []float32, components [][]float32, explained float64) *projection {
+	offsets := make([]float32, len(components))
+	for i, c := range components {
+		offsets[i] = vecmath.Dot(c, mean)
+	}
+	return &projection{mean: mean, components: components, offsets: offsets, explained: explained}
+}
+
+func (p *projection) dims() int {
+	return len(p.components)
+}
+
+func (p *projection) apply(x []float32) []float32 {
+	out := make([]float32, len(p.components))
+	for i, c := range p.components {
+		out[i] = vecmath.Dot(c, x) - p.offsets[i]
+	}
+	return out
+}
+
+func (p *projection) applyAll(points [][]float32) [][]float32 {
+	out := make([][]float32, len(points))
+	vecmath.ParallelFor(len(points), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			out[i] = p.apply(points[i])
+		}
+	})
+	return out
+}
+
+// reconstruct maps a point of the projected space back to the original one.
+func (p *projection) reconstruct(y []float32) []float32 {
+	out := clone(p.mean)
+	for i, c := range p.components {
+		for j, v := range c {
+			out[j] += y[i] * v
+		}
+	}
+	return out
+}
+
+// fitPCA computes the principal axes of points from their covariance matrix.
+// It keeps dims axes, or when dims is 0 the fewest axes that explain at least
+// the variance ratio of the total variance.
+func fitPCA(points [][]float32, dims int, variance float64) (*projection, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("pca: need at least 2 points, got %d", len(points))
+	}
+	d := len(points[0])
+
+	mean := make([]float64, d)
+	for _, p := range points {
+		for j, v := range p {
+			mean[j] += float64(v)
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(points))
+	}
+
+	cov := make([][]float64, d)
+	for i := range cov {
+		cov[i] = make([]float64, d)
+	}
+	centred := make([]float64, d)
+	for _, p := range points {
+		for j, v := range p {
+			centred[j] = float64(v) - mean[j]
+		}
+		for i, ci := range centred {
+			row := cov[i]
+			for j := i; j < d; j++ {
+				row[j] += ci * centred[j]
+			}
+		}
+	}
+	for i := range cov {
+		for j := i; j < d; j++ {
+			cov[i][j] /= float64(len(points) - 1)
+			cov[j][i] = cov[i][j]
+		}
+	}
+
+	values, vectors := symmetricEigen(cov)
+	order := make([]int, d)
+	var total float64
+	for i := range order {
+		order[i] = i
+		values[i] = math.Max(values[i], 0)
+		total += values[i]
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+
+	k := dims
+	if k <= 0 {
+		k = d
+		var kept float64
+		for i, idx := range order {
+			kept += values[idx]
+			if total > 0 && kept/total >= variance {
+				k = i + 1
+				break
+			}
+		}
+	}
+	k = min(k, d)
+
+	components := make([][]float32, k)
+	var kept float64
+	for i := range components {
+		idx := order[i]
+		kept += values[idx]
+		// Eigenvectors are only defined up to sign; pointing the largest
+		// coordinate up keeps the projection reproducible.
+		sign, largest := 1.0, 0.0
+		for j := 0; j < d; j++ {
+			if v := vectors[j][idx]; math.Abs(v) > largest {
+				largest = math.Abs(v)
+				sign = math.Copysign(1, v)
+			}
+		}
+		c := make([]float32, d)
+		for j := range c {
+			c[j] = float32(sign * vectors[j][idx])
+		}
+		components[i] = c
+	}
+
+	explained := 1.0
+	if total > 0 {
+		explained = kept / total
+	}
+	mean32 := make([]float32, d)
+	for j, v := range mean {
+		mean32[j] = float32(v)
+	}
+	return newProjection(mean32, components, explained), nil
+}
+
+// symmetricEigen diagonalises the symmetric matrix a in place with cyclic
+// Jacobi rotations. It returns the eigenvalues and a matrix whose columns are
+// the matching unit eigenvectors.
+func symmetricEigen(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		var off, diag float64
+		for p := 0; p < n; p++ {
+			diag += a[p][p] * a[p][p]
+			for q := p + 1; q < n; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if off <= 1e-24*diag || off == 0 {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p][q]
+				if apq == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * apq)
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				rowP, rowQ := a[p], a[q]
+				for k := 0; k < n; k++ {
+					apk, aqk := rowP[k], rowQ[k]
+					rowP[k] = c*apk - s*aqk
+					rowQ[k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = a[i][i]
+	}
+	return values, v
+}
+
+// liftCentroids replaces centroids fitted in a projected space by the means
+// of their members' original points, so that stored centroids stay
+// comparable with embeddings in the database. Parents average the points of
+// all their descendants; a centroid without members is reconstructed from
+// the projection instead.
+func liftCentroids(points [][]float32, res Result, proj *projection, metric Metric) [][]float32 {
+	d := len(proj.mean)
+	sums := make([][]float64, len(res.Centroids))
+	counts := make([]int, len(res.Centroids))
+	for i := range sums {
+		sums[i] = make([]float64, d)
+	}
+	for i, c := range res.Assignments {
+		if c == NoiseLabel {
+			continue
+		}
+		for j, v := range points[i] {
+			sums[c][j] += float64(v)
+		}
+		counts[c]++
+	}
+	// Parents are stored before their children, so walking backwards folds
+	// every subtree into its root.
+	for i := len(res.Parents) - 1; i >= 0; i-- {
+		if parent := res.Parents[i]; parent >= 0 {
+			for j, v := range sums[i] {
+				sums[parent][j] += v
+			}
+			counts[parent] += counts[i]
+		}
+	}
+
+	out := make([][]float32, len(res.Centroids))
+	for i := range out {
+		if counts[i] == 0 {
+			out[i] = metric.project(proj.reconstruct(res.Centroids[i]))
+			continue
+		}
+		c := make([]float32, d)
+		for j, v := range sums[i] {
+			c[j] = float32(v / float64(counts[i]))
+		}
+		out[i] = metric.project(c)
+	}
+	return out
+}
+
+// projectedModel predicts with a model fitted in a projected space.
+type projectedModel struct {
+	Clusterer
+	proj   *projection
+	metric Metric
+}
+
+func (m projectedModel) Predict(points [][]float32) ([]int, error) {
+	return m.Clusterer.Predict(m.metric.prepare(m.proj.applyAll(points)))
+}
+
+func (p *projection) toModel() cluster.Projection {
+	components := make([]float32, 0, len(p.components)*len(p.mean))
+	for _, c := range p.components {
+		components = append(components, c...)
+	}
+	return cluster.Projection{
+		Dims:              p.dims(),
+		SourceDims:        len(p.mean),
+		ExplainedVariance: p.explained,
+		Mean:              p.mean,
+		Components:        components,
+	}
+}
+
+func projectionFromModel(m cluster.Projection) (*projection, error) {
+	if len(m.Mean) != m.SourceDims || len(m.Components) != m.Dims*m.SourceDims {
+		return nil, fmt.Errorf("projection %d: expected %dx%d components, got %d values", m.ID, m.Dims, m.SourceDims, len(m.Components))
+	}
+	components := make([][]float32, m.Dims)
+	for i := range components {
+		components[i] = m.Components[i*m.SourceDims : (i+1)*m.SourceDims : (i+1)*m.SourceDims]
+	}
+	return newProjection(m.Mean, components, m.ExplainedVariance), nil
+}
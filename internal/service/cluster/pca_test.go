@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/vecmath"
+)
+
+func TestSymmetricEigen(t *testing.T) {
+	rnd := newRand(3)
+	const n = 6
+	a := make([][]float64, n)
+	orig := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n)
+		orig[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			v := rnd.NormFloat64()
+			a[i][j], a[j][i] = v, v
+			orig[i][j], orig[j][i] = v, v
+		}
+	}
+
+	values, vectors := symmetricEigen(a)
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			var av float64
+			for j := 0; j < n; j++ {
+				av += orig[i][j] * vectors[j][k]
+			}
+			if math.Abs(av-values[k]*vectors[i][k]) > 1e-9 {
+				t.Fatalf("eigenpair %d: (Av)[%d] = %f, λv = %f", k, i, av, values[k]*vectors[i][k])
+			}
+		}
+	}
+}
+
+// line returns points spread along direction with a little noise on every
+// coordinate.
+func line(n int, direction []float32) [][]float32 {
+	rnd := newRand(9)
+	points := make([][]float32, n)
+	for i := range points {
+		s := float32(rnd.NormFloat64()) * 10
+		p := make([]float32, len(direction))
+		for j, d := range direction {
+			p[j] = 5 + s*d + float32(rnd.NormFloat64())*0.01
+		}
+		points[i] = p
+	}
+	return points
+}
+
+func TestFitPCAFindsPrincipalAxis(t *testing.T) {
+	direction := []float32{0.6, 0.8, 0}
+	points := line(500, direction)
+
+	for _, tc := range []struct {
+		dims     int
+		variance float64
+	}{{1, 0}, {0, 0.9}} {
+		proj, err := fitPCA(points, tc.dims, tc.variance)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if proj.dims() != 1 || proj.explained < 0.99 {
+			t.Fatalf("dims=%d variance=%.1f: expected one axis explaining almost everything, got %d axes explaining %f", tc.dims, tc.variance, proj.dims(), proj.explained)
+		}
+		if cos := math.Abs(float64(vecmath.Dot(proj.components[0], direction))); cos < 0.999 {
+			t.Fatalf("expected the principal axis along %v, got %v", direction, proj.components[0])
+		}
+		back := proj.reconstruct(proj.apply(points[0]))
+		if d := MetricL2.Distance(back, points[0]); d > 0.1 {
+			t.Fatalf("expected reconstruction close to %v, got %v", points[0], back)
+		}
+	}
+}
+
+type projectingRepo struct {
+	memClusterRepo
+	projections []cluster.Projection
+}
+
+func (p *projectingRepo) Nearest(ctx context.Context, embedding []float32, metric string) (cluster.Cluster, float64, error) {
+	return cluster.Cluster{}, 0, errors.New("nearest must not be used with a projection")
+}
+
+func (p *projectingRepo) Leaves(ctx context.Context, metric string) ([]cluster.Cluster, error) {
+	return p.clusters, nil
+}
+
+func (p *projectingRepo) SaveProjection(ctx context.Context, proj cluster.Projection) (int64, error) {
+	proj.ID = int64(len(p.projections) + 1)
+	p.projections = append(p.projections, proj)
+	return proj.ID, nil
+}
+
+func (p *projectingRepo) ActiveProjection(ctx context.Context) (*cluster.Projection, error) {
+	if len(p.projections) == 0 {
+		return nil, nil
+	}
+	return &p.projections[len(p.projections)-1], nil
+}
+
+func TestProjectionIsStoredAndUsedForIncrementalAssignment(t *testing.T) {
+	rnd := newRand(4)
+	var points [][]float32
+	var ids []int64
+	for i := 0; i < 100; i++ {
+		x := float32(0)
+		if i%2 == 1 {
+			x = 10
+		}
+		points = append(points, []float32{x + float32(rnd.NormFloat64())*0.1, float32(rnd.NormFloat64()) * 0.01, float32(rnd.NormFloat64()) * 0.01})
+		ids = append(ids, int64(i+1))
+	}
+
+	repo := &projectingRepo{}
+	docs := &recordingDocRepo{assigned: map[int64]int64{}}
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "l2"
+	cfg.K = 2
+	cfg.PCADims = 1
+	cfg.SpawnDistance = 5
+	svc, err := NewService(repo, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary, err := svc.fitBatch(context.Background(), 1, 1, ids, points)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Diagnostics.PCADims != 1 {
+		t.Fatalf("expected the fit to run on 1 dimension, got %+v", summary.Diagnostics)
+	}
+	if len(repo.projections) != 1 || repo.projections[0].RunID != 1 || repo.projections[0].SourceDims != 3 {
+		t.Fatalf("expected the projection of run 1 to be saved, got %+v", repo.projections)
+	}
+	for _, c := range repo.clusters {
+		if len(c.Centroid) != 3 {
+			t.Fatalf("expected centroids in the original space, got %v", c.Centroid)
+		}
+	}
+
+	// Far from every centroid in full space, but on top of the first blob
+	// along the only projected axis.
+	if _, err := svc.assignIncremental(context.Background(), 2, 1, []int64{1000}, [][]float32{{0, 0, 50}}, MetricL2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.clusters) != 2 {
+		t.Fatalf("expected no cluster to be spawned, got %d clusters", len(repo.clusters))
+	}
+	if docs.assigned[1000] != docs.assigned[1] {
+		t.Fatalf("expected doc 1000 to join doc 1's cluster %d, got %d", docs.assigned[1], docs.assigned[1000])
+	}
+}
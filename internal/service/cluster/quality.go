@@ -0,0 +1,205 @@
+package cluster
+
+import "NeoBIT/internal/vecmath"
+
+// silhouette returns the mean silhouette coefficient of the clustering.
+// Noise points are ignored; points in singleton clusters score 0.
+func silhouette(points [][]float32, assignments []int, metric Metric) float64 {
+	dist := pairDistance(points, metric)
+	return silhouetteWith(assignments, func(i, j int) float64 {
+		return float64(dist(i, j))
+	})
+}
+
+// pairDistance returns the distance between points i and j under metric.
+// Cosine reuses norms computed once per point instead of per pair.
+func pairDistance(points [][]float32, metric Metric) func(i, j int) float32 {
+	switch metric {
+	case MetricCosine:
+		norms := vecmath.Norms(points)
+		return func(i, j int) float32 {
+			return vecmath.CosineDistance(points[i], points[j], norms[i], norms[j])
+		}
+	case MetricInnerProduct:
+		return func(i, j int) float32 {
+			return -vecmath.Dot(points[i], points[j])
+		}
+	default:
+		return func(i, j int) float32 {
+			return vecmath.Sqrt(vecmath.SquaredL2(points[i], points[j]))
+		}
+	}
+}
+
+func silhouetteWith(assignments []int, dist func(i, j int) float64) float64 {
+	clusters := make(map[int][]int)
+	for i, a := range assignments {
+		if a == NoiseLabel {
+			continue
+		}
+		clusters[a] = append(clusters[a], i)
+	}
+	if len(clusters) < 2 {
+		return 0
+	}
+
+	var total float64
+	var n int
+	for i, a := range assignments {
+		if a == NoiseLabel {
+			continue
+		}
+		n++
+		if len(clusters[a]) == 1 {
+			continue
+		}
+
+		var intra float64
+		inter := -1.0
+		for label, members := range clusters {
+			var sum float64
+			for _, j := range members {
+				if j != i {
+					sum += dist(i, j)
+				}
+			}
+			if label == a {
+				intra = sum / float64(len(members)-1)
+				continue
+			}
+			mean := sum / float64(len(members))
+			if inter < 0 || mean < inter {
+				inter = mean
+			}
+		}
+
+		den := intra
+		if inter > den {
+			den = inter
+		}
+		if den > 0 {
+			total += (inter - intra) / den
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return total / float64(n)
+}
+
+// pairwiseDistances returns the full distance matrix of points, sharded by
+// row across CPUs. Row i fills the upper triangle and mirrors it into column
+// i, so every cell is written by exactly one shard.
+func pairwiseDistances(points [][]float32, metric Metric) [][]float32 {
+	out := make([][]float32, len(points))
+	for i := range points {
+		out[i] = make([]float32, len(points))
+	}
+	dist := pairDistance(points, metric)
+	vecmath.ParallelFor(len(points), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			for j := i + 1; j < len(points); j++ {
+				d := dist(i, j)
+				out[i][j] = d
+				out[j][i] = d
+			}
+		}
+	})
+	return out
+}
+
+func samplePoints(points [][]float32, n int, opts Options) [][]float32 {
+	if n <= 0 || n >= len(points) {
+		return points
+	}
+	idx := opts.Rand.Perm(len(points))[:n]
+	out := make([][]float32, n)
+	for i, j := range idx {
+		out[i] = points[j]
+	}
+	return out
+}
+
+type Quality struct {
+	Inertia       float64
+	Silhouette    float64
+	DaviesBouldin float64
+	Cohesion      []*float64
+}
+
+// evaluate scores a clustering. Inertia is the within-cluster sum of squared
+// Euclidean distances; cohesion (mean member-to-centroid distance) and the
+// Davies-Bouldin index use the clustering metric. Silhouette is computed on a
+// random sample of at most sampleSize points because it is quadratic.
+func evaluate(points [][]float32, assignments []int, centroids [][]float32, metric Metric, sampleSize int, opts Options) Quality {
+	q := Quality{Cohesion: make([]*float64, len(centroids))}
+
+	sums := make([]float64, len(centroids))
+	counts := make([]int, len(centroids))
+	for i, p := range points {
+		a := assignments[i]
+		if a == NoiseLabel {
+			continue
+		}
+		q.Inertia += float64(vecmath.SquaredL2(p, centroids[a]))
+		sums[a] += float64(metric.Distance(p, centroids[a]))
+		counts[a]++
+	}
+	for c := range centroids {
+		if counts[c] > 0 {
+			v := sums[c] / float64(counts[c])
+			q.Cohesion[c] = &v
+		}
+	}
+	q.DaviesBouldin = daviesBouldin(centroids, q.Cohesion, metric)
+
+	sampleIdx := make([]int, 0, len(points))
+	for i, a := range assignments {
+		if a != NoiseLabel {
+			sampleIdx = append(sampleIdx, i)
+		}
+	}
+	if sampleSize > 0 && len(sampleIdx) > sampleSize {
+		opts.Rand.Shuffle(len(sampleIdx), func(i, j int) {
+			sampleIdx[i], sampleIdx[j] = sampleIdx[j], sampleIdx[i]
+		})
+		sampleIdx = sampleIdx[:sampleSize]
+	}
+	sample := make([][]float32, len(sampleIdx))
+	sampleAssignments := make([]int, len(sampleIdx))
+	for i, j := range sampleIdx {
+		sample[i] = points[j]
+		sampleAssignments[i] = assignments[j]
+	}
+	q.Silhouette = silhouette(sample, sampleAssignments, metric)
+	return q
+}
+
+func daviesBouldin(centroids [][]float32, cohesion []*float64, metric Metric) float64 {
+	var total float64
+	var n int
+	for i := range centroids {
+		if cohesion[i] == nil {
+			continue
+		}
+		worst := 0.0
+		for j := range centroids {
+			if i == j || cohesion[j] == nil {
+				continue
+			}
+			sep := float64(metric.Distance(centroids[i], centroids[j]))
+			if sep <= 0 {
+				continue
+			}
+			if r := (*cohesion[i] + *cohesion[j]) / sep; r > worst {
+				worst = r
+			}
+		}
+		total += worst
+		n++
+	}
+	if n < 2 {
+		return 0
+	}
+	return total / float64(n)
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSilhouette(t *testing.T) {
+	points := [][]float32{{0, 0}, {0, 1}, {10, 0}, {10, 1}}
+	good := silhouette(points, []int{0, 0, 1, 1}, MetricL2)
+	bad := silhouette(points, []int{0, 1, 0, 1}, MetricL2)
+	if good < 0.85 {
+		t.Fatalf("expected high silhouette for separated clusters, got %f", good)
+	}
+	if bad >= 0 {
+		t.Fatalf("expected negative silhouette for mixed clusters, got %f", bad)
+	}
+	if s := silhouette(points, []int{0, 0, 0, 0}, MetricL2); s != 0 {
+		t.Fatalf("expected 0 for a single cluster, got %f", s)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	points := [][]float32{{0, 0}, {0, 2}, {10, 0}, {10, 2}}
+	centroids := [][]float32{{0, 1}, {10, 1}}
+	opts := Options{Rand: newRand(1)}
+
+	q := evaluate(points, []int{0, 0, 1, 1}, centroids, MetricL2, 0, opts)
+	if q.Inertia != 4 {
+		t.Fatalf("expected inertia 4, got %f", q.Inertia)
+	}
+	for i, c := range q.Cohesion {
+		if c == nil || *c != 1 {
+			t.Fatalf("expected cohesion 1 for cluster %d, got %v", i, c)
+		}
+	}
+	if q.DaviesBouldin != 0.2 {
+		t.Fatalf("expected davies-bouldin 0.2, got %f", q.DaviesBouldin)
+	}
+	if q.Silhouette < 0.8 {
+		t.Fatalf("expected high silhouette, got %f", q.Silhouette)
+	}
+
+	q = evaluate(points, []int{0, 0, NoiseLabel, NoiseLabel}, centroids, MetricL2, 0, opts)
+	if q.Cohesion[1] != nil {
+		t.Fatalf("expected nil cohesion for empty cluster, got %f", *q.Cohesion[1])
+	}
+	if q.DaviesBouldin != 0 {
+		t.Fatalf("expected davies-bouldin 0 for a single cluster, got %f", q.DaviesBouldin)
+	}
+}
+
+func TestPairwiseDistancesMatchMetric(t *testing.T) {
+	// More points than one shard, so the matrix is filled in parallel.
+	points := randomPoints(1100, 8, 3)
+	for _, metric := range []Metric{MetricCosine, MetricL2, MetricInnerProduct} {
+		dists := pairwiseDistances(points, metric)
+		for _, ij := range [][2]int{{0, 1}, {5, 1099}, {1099, 5}, {600, 601}} {
+			want := metric.Distance(points[ij[0]], points[ij[1]])
+			if got := dists[ij[0]][ij[1]]; math.Abs(float64(got-want)) > 1e-4 {
+				t.Fatalf("%s %v: expected %f, got %f", metric, ij, want, got)
+			}
+		}
+		if dists[7][7] != 0 {
+			t.Fatalf("%s: expected a zero diagonal, got %f", metric, dists[7][7])
+		}
+	}
+}
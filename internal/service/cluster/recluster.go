@@ -0,0 +1,194 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+var ErrReclusterRunning = errors.New("full recluster is already running")
+
+// Recluster fits a new model over the whole corpus and stages its clusters
+// and assignments in a new generation, which is swapped in atomically once
+// every document has been assigned. Readers keep seeing the previous
+// generation until then; on failure the staged generation is discarded.
+func (s *ClusterService) Recluster(ctx context.Context) (cluster.Run, error) {
+	if s.clusterRepo == nil || s.docRepo == nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: repo is nil")
+	}
+	unlock, err := s.lockRecluster(ctx)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	defer unlock()
+	return s.reclusterLocked(ctx)
+}
+
+// lockRecluster claims the single full recluster, in this process and across
+// instances, or fails with ErrReclusterRunning. The returned function
+// releases it.
+func (s *ClusterService) lockRecluster(ctx context.Context) (func(), error) {
+	if !s.reclusterMu.TryLock() {
+		return nil, ErrReclusterRunning
+	}
+	unlock, ok, err := s.tryLockRecluster(ctx)
+	if err != nil {
+		s.reclusterMu.Unlock()
+		return nil, fmt.Errorf("cluster service: lock recluster: %w", err)
+	}
+	if !ok {
+		s.reclusterMu.Unlock()
+		return nil, ErrReclusterRunning
+	}
+	return func() {
+		unlock()
+		s.reclusterMu.Unlock()
+	}, nil
+}
+
+// reclusterLocked runs a full recluster; the caller holds lockRecluster.
+func (s *ClusterService) reclusterLocked(ctx context.Context) (cluster.Run, error) {
+	seed := resolveSeed(s.cfg.Seed)
+	runID, err := s.startRun(ctx, cluster.RunKindFull, seed, nil)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: start recluster run: %w", err)
+	}
+	summary, err := s.recluster(ctx, runID, seed)
+	s.finishRun(ctx, runID, summary, err)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	if _, err := s.CleanupEmptyClusters(ctx); err != nil {
+		s.log.Warn(ctx, "cluster recluster: cleanup empty clusters failed", logger.FieldAny("error", err))
+	}
+	if pruned, err := s.clusterRepo.PruneGenerations(ctx, s.cfg.KeepGenerations); err != nil {
+		s.log.Warn(ctx, "cluster recluster: prune generations failed", logger.FieldAny("error", err))
+	} else if pruned > 0 {
+		s.log.Info(ctx, "cluster recluster: pruned retired generations", logger.FieldAny("generations", pruned))
+	}
+	s.refreshMetrics(ctx)
+	return s.clusterRepo.GetRun(ctx, runID)
+}
+
+func (s *ClusterService) recluster(ctx context.Context, runID, seed int64) (runSummary, error) {
+	generationID, err := s.clusterRepo.CreateGeneration(ctx, runID)
+	if err != nil {
+		return runSummary{}, fmt.Errorf("create generation: %w", err)
+	}
+
+	summary, err := s.stageGeneration(ctx, runID, generationID, seed)
+	if err == nil {
+		// Hold the workers off so that no batch straddles the swap.
+		err = s.swapGeneration(ctx, generationID, runID)
+		if err == nil {
+			s.log.Info(ctx, "cluster recluster: generation activated",
+				logger.FieldAny("run_id", runID),
+				logger.FieldAny("generation_id", generationID),
+				logger.FieldAny("docs", summary.Docs),
+			)
+			return summary, nil
+		}
+		err = fmt.Errorf("activate generation: %w", err)
+	}
+
+	if derr := s.clusterRepo.DiscardGeneration(context.WithoutCancel(ctx), generationID); derr != nil {
+		s.log.Error(ctx, "cluster recluster: discard generation failed", logger.FieldAny("generation_id", generationID), logger.FieldAny("error", derr))
+	}
+	return summary, err
+}
+
+func (s *ClusterService) swapGeneration(ctx context.Context, generationID, runID int64) error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	unlock, err := s.lockWrites(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	_, err = s.clusterRepo.ActivateGeneration(ctx, generationID, runID)
+	return err
+}
+
+func (s *ClusterService) stageGeneration(ctx context.Context, runID, generationID, seed int64) (runSummary, error) {
+	sampleSize := s.cfg.ReclusterSample
+	if sampleSize <= 0 {
+		sampleSize = 20000
+	}
+	sample, err := s.corpusSampler()(ctx, sampleSize)
+	if err != nil {
+		return runSummary{}, fmt.Errorf("sample corpus: %w", err)
+	}
+	if len(sample) == 0 {
+		return runSummary{}, fmt.Errorf("no documents to cluster")
+	}
+	sample = s.metric.prepare(sample)
+
+	s.log.Info(ctx, "cluster recluster: fitting", logger.FieldAny("run_id", runID), logger.FieldAny("sample", len(sample)))
+	model, res, quality, err := s.fit(ctx, s.options(seed, len(sample)), sample)
+	if err != nil {
+		return runSummary{}, err
+	}
+	summary := runSummary{K: res.leafCount(), Diagnostics: res.Diagnostics}
+	summary.Diagnostics.Noise = 0
+
+	clusterIDs, err := s.createClusters(ctx, runID, &generationID, res, quality)
+	if err != nil {
+		return summary, err
+	}
+	if err := s.saveProjection(ctx, runID, &generationID, res); err != nil {
+		return summary, err
+	}
+
+	pageSize := s.batchSize()
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		docs, err := s.docRepo.ListEmbeddingsAfter(ctx, afterID, pageSize)
+		if err != nil {
+			return summary, fmt.Errorf("list documents: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+		afterID = docs[len(docs)-1].ID
+
+		points := make([][]float32, len(docs))
+		for i, doc := range docs {
+			points[i] = doc.Embedding
+		}
+		labels, err := model.Predict(s.metric.prepare(points))
+		if err != nil {
+			return summary, fmt.Errorf("predict: %w", err)
+		}
+
+		buckets := make(map[int64][]int64)
+		var noise []int64
+		for i, label := range labels {
+			if label == NoiseLabel {
+				noise = append(noise, docs[i].ID)
+				continue
+			}
+			buckets[clusterIDs[label]] = append(buckets[clusterIDs[label]], docs[i].ID)
+		}
+		for clusterID, docIDs := range buckets {
+			if err := s.clusterRepo.SaveAssignments(ctx, runID, docIDs, &clusterID); err != nil {
+				return summary, fmt.Errorf("stage assignments: %w", err)
+			}
+		}
+		if err := s.clusterRepo.SaveAssignments(ctx, runID, noise, nil); err != nil {
+			return summary, fmt.Errorf("stage noise: %w", err)
+		}
+		summary.Docs += len(docs)
+		summary.Diagnostics.Noise += len(noise)
+		s.job.advance(len(docs))
+		s.log.Info(ctx, "cluster recluster: staged page", logger.FieldAny("run_id", runID), logger.FieldAny("docs", summary.Docs))
+	}
+
+	s.recordQuality(ctx, runID, s.cfg.Algorithm, summary.K, len(sample), quality, clusterIDs)
+	return summary, nil
+}
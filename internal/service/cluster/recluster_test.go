@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/models/document"
+)
+
+type generationRepo struct {
+	runRecordingRepo
+	activated []int64
+	discarded []int64
+	pruned    []int
+}
+
+func (g *generationRepo) CreateGeneration(ctx context.Context, runID int64) (int64, error) {
+	return 2, nil
+}
+
+func (g *generationRepo) ActivateGeneration(ctx context.Context, generationID, runID int64) (int64, error) {
+	g.activated = append(g.activated, generationID)
+	return int64(len(g.assignments[runID])), nil
+}
+
+func (g *generationRepo) DiscardGeneration(ctx context.Context, generationID int64) error {
+	g.discarded = append(g.discarded, generationID)
+	return nil
+}
+
+func (g *generationRepo) PruneGenerations(ctx context.Context, keep int) (int64, error) {
+	g.pruned = append(g.pruned, keep)
+	return 0, nil
+}
+
+type corpusDocRepo struct {
+	recordingDocRepo
+	docs    []document.Document
+	pageErr error
+}
+
+func (c *corpusDocRepo) Count(ctx context.Context) (int64, error) {
+	return int64(len(c.docs)), nil
+}
+
+func (c *corpusDocRepo) SampleEmbeddings(ctx context.Context, fraction float64, limit int) ([][]float32, error) {
+	out := make([][]float32, 0, len(c.docs))
+	for _, doc := range c.docs {
+		out = append(out, doc.Embedding)
+	}
+	return out, nil
+}
+
+func (c *corpusDocRepo) ListEmbeddingsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error) {
+	if c.pageErr != nil && afterID > 0 {
+		return nil, c.pageErr
+	}
+	var out []document.Document
+	for _, doc := range c.docs {
+		if doc.ID > afterID && len(out) < limit {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+func newReclusterFixture(t *testing.T) (*ClusterService, *generationRepo, *corpusDocRepo) {
+	t.Helper()
+	clusters := &generationRepo{runRecordingRepo: runRecordingRepo{assignments: map[int64]map[int64]*int64{}}}
+	docs := &corpusDocRepo{
+		recordingDocRepo: recordingDocRepo{assigned: map[int64]int64{}},
+		docs: []document.Document{
+			{ID: 1, Embedding: []float32{0, 0}},
+			{ID: 2, Embedding: []float32{0, 1}},
+			{ID: 3, Embedding: []float32{10, 0}},
+			{ID: 4, Embedding: []float32{10, 1}},
+			{ID: 5, Embedding: []float32{10, 2}},
+		},
+	}
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "l2"
+	cfg.K = 2
+	cfg.Seed = 3
+	cfg.BatchSize = 2
+	svc, err := NewService(clusters, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return svc, clusters, docs
+}
+
+func TestRecluster(t *testing.T) {
+	svc, clusters, docs := newReclusterFixture(t)
+
+	if _, err := svc.Recluster(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(clusters.activated) != 1 || clusters.activated[0] != 2 || len(clusters.discarded) != 0 {
+		t.Fatalf("expected generation 2 activated, got activated=%v discarded=%v", clusters.activated, clusters.discarded)
+	}
+	for _, c := range clusters.clusters {
+		if c.GenerationID == nil || *c.GenerationID != 2 {
+			t.Fatalf("expected cluster staged in generation 2, got %v", c.GenerationID)
+		}
+	}
+	staged := clusters.assignments[1]
+	if len(staged) != 5 {
+		t.Fatalf("expected all 5 documents staged, got %d", len(staged))
+	}
+	if *staged[1] != *staged[2] || *staged[3] != *staged[5] || *staged[1] == *staged[3] {
+		t.Fatalf("unexpected staged assignments: %v", staged)
+	}
+	if len(docs.assigned) != 0 {
+		t.Fatalf("expected documents untouched before the swap, got %v", docs.assigned)
+	}
+	if f := clusters.finished[0]; f.DocsProcessed != 5 || f.Status != cluster.RunStatusSucceeded {
+		t.Fatalf("unexpected finished run: %+v", f)
+	}
+	if len(clusters.pruned) != 1 || clusters.pruned[0] != svc.cfg.KeepGenerations {
+		t.Fatalf("expected retired generations pruned once, got %v", clusters.pruned)
+	}
+}
+
+func TestReclusterDiscardsOnFailure(t *testing.T) {
+	svc, clusters, docs := newReclusterFixture(t)
+	docs.pageErr = errors.New("boom")
+
+	if _, err := svc.Recluster(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+	if len(clusters.activated) != 0 || len(clusters.discarded) != 1 {
+		t.Fatalf("expected generation discarded, got activated=%v discarded=%v", clusters.activated, clusters.discarded)
+	}
+	if f := clusters.finished[0]; f.Status != cluster.RunStatusFailed {
+		t.Fatalf("expected failed run, got %+v", f)
+	}
+	if len(clusters.pruned) != 0 {
+		t.Fatalf("expected no pruning after a failed recluster, got %v", clusters.pruned)
+	}
+}
@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+)
+
+var (
+	ErrRunNotSucceeded = errors.New("cluster run did not succeed")
+	ErrRunPruned       = errors.New("cluster run's generation was pruned")
+)
+
+type runSummary struct {
+	K           int
+	Docs        int
+	Diagnostics Diagnostics
+}
+
+type runParams struct {
+	K              int     `json:"k"`
+	AutoK          bool    `json:"auto_k"`
+	BatchSize      int     `json:"batch_size"`
+	MaxIterations  int     `json:"max_iterations"`
+	Tolerance      float64 `json:"tolerance"`
+	Init           string  `json:"init"`
+	MiniBatchSize  int     `json:"minibatch_size"`
+	MiniBatchIters int     `json:"minibatch_iters"`
+	Eps            float64 `json:"eps"`
+	MinPts         int     `json:"min_pts"`
+	SpawnDistance  float64 `json:"spawn_distance"`
+	TargetRunID    int64   `json:"target_run_id,omitempty"`
+	SourceCluster  int64   `json:"source_cluster_id,omitempty"`
+	TargetCluster  int64   `json:"target_cluster_id,omitempty"`
+}
+
+func (s *ClusterService) ListRuns(ctx context.Context, limit, offset int) ([]cluster.Run, error) {
+	if s.clusterRepo == nil {
+		return nil, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	return s.clusterRepo.ListRuns(ctx, limit, offset)
+}
+
+func (s *ClusterService) GetRun(ctx context.Context, id int64) (cluster.Run, error) {
+	if s.clusterRepo == nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	return s.clusterRepo.GetRun(ctx, id)
+}
+
+// Rollback restores document assignments to the state left by run id and
+// records the rollback as a run of its own.
+func (s *ClusterService) Rollback(ctx context.Context, id int64) (cluster.Run, error) {
+	if s.clusterRepo == nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	target, err := s.clusterRepo.GetRun(ctx, id)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	if target.Status != cluster.RunStatusSucceeded {
+		return cluster.Run{}, fmt.Errorf("cluster service: rollback to run %d: %w", id, ErrRunNotSucceeded)
+	}
+	if target.GenerationID == nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: rollback to run %d: %w", id, ErrRunPruned)
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	unlock, err := s.lockWrites(ctx, true)
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: lock rollback: %w", err)
+	}
+	defer unlock()
+
+	runID, err := s.startRun(ctx, cluster.RunKindRollback, target.Seed, func(p *runParams) { p.TargetRunID = id })
+	if err != nil {
+		return cluster.Run{}, fmt.Errorf("cluster service: start rollback run: %w", err)
+	}
+	restored, err := s.clusterRepo.Rollback(ctx, runID, id)
+	s.finishRun(ctx, runID, runSummary{K: target.K, Docs: int(restored)}, err)
+	if err != nil {
+		return cluster.Run{}, err
+	}
+	s.refreshMetrics(ctx)
+	return s.clusterRepo.GetRun(ctx, runID)
+}
+
+// startRun records a running run with the current configuration; edit, if
+// set, adds what the run acts on to its params.
+func (s *ClusterService) startRun(ctx context.Context, kind string, seed int64, edit func(p *runParams)) (int64, error) {
+	p := runParams{
+		K:              s.cfg.K,
+		AutoK:          s.cfg.AutoK,
+		BatchSize:      s.cfg.BatchSize,
+		MaxIterations:  s.cfg.MaxIterations,
+		Tolerance:      s.cfg.Tolerance,
+		Init:           s.cfg.Init,
+		MiniBatchSize:  s.cfg.MiniBatchSize,
+		MiniBatchIters: s.cfg.MiniBatchIters,
+		Eps:            s.cfg.Eps,
+		MinPts:         s.cfg.MinPts,
+		SpawnDistance:  s.cfg.SpawnDistance,
+	}
+	if edit != nil {
+		edit(&p)
+	}
+	params, err := json.Marshal(p)
+	if err != nil {
+		return 0, fmt.Errorf("marshal run params: %w", err)
+	}
+	runID, err := s.clusterRepo.CreateRun(ctx, cluster.Run{
+		Kind:      kind,
+		Algorithm: s.cfg.Algorithm,
+		Metric:    string(s.metric),
+		K:         s.cfg.K,
+		Seed:      seed,
+		Params:    params,
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.job.addRun(runID)
+	return runID, nil
+}
+
+func (s *ClusterService) finishRun(ctx context.Context, runID int64, summary runSummary, runErr error) {
+	run := cluster.Run{
+		ID:            runID,
+		Status:        cluster.RunStatusSucceeded,
+		K:             summary.K,
+		DocsProcessed: summary.Docs,
+	}
+	if runErr != nil {
+		msg := runErr.Error()
+		run.Status = cluster.RunStatusFailed
+		run.Error = &msg
+		s.log.Error(ctx, "cluster worker: run failed", logger.FieldAny("run_id", runID), logger.FieldAny("error", runErr))
+	}
+	diagnostics, err := json.Marshal(summary.Diagnostics)
+	if err != nil {
+		s.log.Error(ctx, "cluster worker: marshal diagnostics failed", logger.FieldAny("error", err))
+	}
+	run.Diagnostics = diagnostics
+
+	// The run outcome must be recorded even if the caller's context was
+	// cancelled mid-run.
+	if err := s.clusterRepo.FinishRun(context.WithoutCancel(ctx), run); err != nil {
+		s.log.Error(ctx, "cluster worker: finish run failed", logger.FieldAny("run_id", runID), logger.FieldAny("error", err))
+	}
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/models/document"
+)
+
+type runRecordingRepo struct {
+	memClusterRepo
+	runs        []cluster.Run
+	finished    []cluster.Run
+	assignments map[int64]map[int64]*int64
+}
+
+func (r *runRecordingRepo) CreateRun(ctx context.Context, run cluster.Run) (int64, error) {
+	run.ID = int64(len(r.runs) + 1)
+	r.runs = append(r.runs, run)
+	return run.ID, nil
+}
+
+func (r *runRecordingRepo) FinishRun(ctx context.Context, run cluster.Run) error {
+	r.finished = append(r.finished, run)
+	return nil
+}
+
+func (r *runRecordingRepo) SaveAssignments(ctx context.Context, runID int64, docIDs []int64, clusterID *int64) error {
+	if r.assignments[runID] == nil {
+		r.assignments[runID] = map[int64]*int64{}
+	}
+	for _, id := range docIDs {
+		r.assignments[runID][id] = clusterID
+	}
+	return nil
+}
+
+type batchDocRepo struct {
+	recordingDocRepo
+	docs []document.Document
+}
+
+func (b *batchDocRepo) ClaimUnclustered(ctx context.Context, limit int, lease time.Duration) ([]document.Document, error) {
+	return b.docs, nil
+}
+
+func TestProcessBatchRecordsRun(t *testing.T) {
+	clusters := &runRecordingRepo{assignments: map[int64]map[int64]*int64{}}
+	docs := &batchDocRepo{
+		recordingDocRepo: recordingDocRepo{assigned: map[int64]int64{}},
+		docs: []document.Document{
+			{ID: 1, Embedding: []float32{0, 0}},
+			{ID: 2, Embedding: []float32{0, 1}},
+			{ID: 3, Embedding: []float32{10, 0}},
+			{ID: 4, Embedding: []float32{10, 1}},
+		},
+	}
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "l2"
+	cfg.K = 2
+	cfg.Seed = 7
+	svc, err := NewService(clusters, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.processBatch(context.Background())
+
+	if len(clusters.runs) != 1 || clusters.runs[0].Kind != cluster.RunKindBatch || clusters.runs[0].Seed != 7 {
+		t.Fatalf("expected one seeded batch run, got %+v", clusters.runs)
+	}
+	if len(clusters.finished) != 1 {
+		t.Fatalf("expected run to be finished, got %d", len(clusters.finished))
+	}
+	finished := clusters.finished[0]
+	if finished.Status != cluster.RunStatusSucceeded || finished.DocsProcessed != 4 || finished.K != 2 {
+		t.Fatalf("unexpected finished run: %+v", finished)
+	}
+	for _, c := range clusters.clusters {
+		if c.RunID == nil || *c.RunID != 1 {
+			t.Fatalf("expected cluster to reference run 1, got %v", c.RunID)
+		}
+	}
+	for id, clusterID := range docs.assigned {
+		if got := clusters.assignments[1][id]; got == nil || *got != clusterID {
+			t.Fatalf("expected assignment of doc %d to cluster %d recorded, got %v", id, clusterID, got)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"NeoBIT/internal/config"
 	"NeoBIT/internal/logger"
@@ -13,15 +15,79 @@ import (
 type ClusterService struct {
 	clusterRepo ClusterRepository
 	docRepo     DocumentRepository
+	tx          Transactor
+	locker      Locker
 	cfg         config.ClusterConfig
+	factory     Factory
+	metric      Metric
+	labelsNoise bool
+	runMu       *sync.Mutex
+	reclusterMu *sync.Mutex
+	job         *jobState
 	log         logger.Logger
 }
 
-func NewService(clusterRepo ClusterRepository, docRepo DocumentRepository, cfg config.ClusterConfig, log logger.Logger) *ClusterService {
+// NewService builds the clustering service. tx may be nil, in which case
+// batches are not written atomically; locker may be nil when only one
+// instance runs.
+func NewService(clusterRepo ClusterRepository, docRepo DocumentRepository, tx Transactor, locker Locker, cfg config.ClusterConfig, log logger.Logger) (*ClusterService, error) {
 	if log == nil {
 		log = logger.Nop()
 	}
-	return &ClusterService{clusterRepo: clusterRepo, docRepo: docRepo, cfg: cfg, log: log}
+	factory, err := Lookup(cfg.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("cluster service: %w", err)
+	}
+	metric, err := ParseMetric(cfg.Metric)
+	if err != nil {
+		return nil, fmt.Errorf("cluster service: %w", err)
+	}
+	if err := validateProjection(cfg); err != nil {
+		return nil, fmt.Errorf("cluster service: %w", err)
+	}
+	_, labelsNoise := factory(Options{Config: cfg, Metric: metric}).(noiseLabeler)
+	return &ClusterService{
+		clusterRepo: clusterRepo,
+		docRepo:     docRepo,
+		tx:          tx,
+		locker:      locker,
+		cfg:         cfg,
+		factory:     factory,
+		metric:      metric,
+		labelsNoise: labelsNoise,
+		runMu:       &sync.Mutex{},
+		reclusterMu: &sync.Mutex{},
+		log:         log,
+	}, nil
+}
+
+// validateProjection rejects PCA settings fitPCA cannot honour. Zero leaves
+// either setting off; a variance share must lie strictly between 0 and 1.
+func validateProjection(cfg config.ClusterConfig) error {
+	if cfg.PCADims < 0 {
+		return fmt.Errorf("cluster: pca dims must not be negative, got %d", cfg.PCADims)
+	}
+	if cfg.PCAVariance != 0 && (cfg.PCAVariance <= 0 || cfg.PCAVariance >= 1) {
+		return fmt.Errorf("cluster: pca variance must be in (0, 1), got %v", cfg.PCAVariance)
+	}
+	return nil
+}
+
+// withConfig returns a service sharing repositories and locks with s but
+// clustering with cfg. The metric cannot change, since incremental
+// assignment compares against stored centroids.
+func (s *ClusterService) withConfig(cfg config.ClusterConfig) (*ClusterService, error) {
+	factory, err := Lookup(cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Metric = string(s.metric)
+	_, labelsNoise := factory(Options{Config: cfg, Metric: s.metric}).(noiseLabeler)
+	c := *s
+	c.cfg = cfg
+	c.factory = factory
+	c.labelsNoise = labelsNoise
+	return &c, nil
 }
 
 func (s *ClusterService) List(ctx context.Context, limit, offset int) ([]cluster.Cluster, error) {
@@ -31,20 +97,17 @@ func (s *ClusterService) List(ctx context.Context, limit, offset int) ([]cluster
 	return s.clusterRepo.List(ctx, limit, offset)
 }
 
-func (s *ClusterService) processBatch(ctx context.Context) {
-	batchSize := s.cfg.BatchSize
-	if batchSize <= 0 {
-		batchSize = 1000
-	}
-
-	docs, err := s.docRepo.ListUnclustered(ctx, batchSize)
+// processBatch clusters one batch of unclustered documents and returns how
+// many it picked up; 0 means there was nothing to do.
+func (s *ClusterService) processBatch(ctx context.Context) (int, error) {
+	docs, err := s.docRepo.ClaimUnclustered(ctx, s.batchSize(), s.leaseTTL())
 	if err != nil {
-		s.log.Error(ctx, "cluster worker: failed to list unclustered", logger.FieldAny("error", err))
-		return
+		s.log.Error(ctx, "cluster worker: failed to claim unclustered", logger.FieldAny("error", err))
+		return 0, err
 	}
 	if len(docs) == 0 {
-		s.log.Info(ctx, "cluster worker: no unclustered documents")
-		return
+		s.log.Debug(ctx, "cluster worker: no unclustered documents")
+		return 0, nil
 	}
 
 	points := make([][]float32, 0, len(docs))
@@ -58,48 +121,378 @@ func (s *ClusterService) processBatch(ctx context.Context) {
 	}
 	if len(points) == 0 {
 		s.log.Warn(ctx, "cluster worker: no embeddings in batch")
-		return
+		return 0, nil
+	}
+
+	points = s.metric.prepare(points)
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	kind, unlock, err := s.lockBatch(ctx)
+	if err != nil {
+		s.log.Error(ctx, "cluster worker: lock batch failed", logger.FieldAny("error", err))
+		s.releaseLeases(ctx, ids)
+		return 0, err
+	}
+	defer unlock()
+
+	seed := resolveSeed(s.cfg.Seed)
+	runID, err := s.startRun(ctx, kind, seed, nil)
+	if err != nil {
+		s.log.Error(ctx, "cluster worker: start run failed", logger.FieldAny("error", err))
+		s.releaseLeases(ctx, ids)
+		return 0, err
+	}
+
+	var summary runSummary
+	if kind == cluster.RunKindIncremental {
+		summary, err = s.assignIncremental(ctx, runID, seed, ids, points, s.metric)
+	} else {
+		summary, err = s.fitBatch(ctx, runID, seed, ids, points)
+	}
+	s.finishRun(ctx, runID, summary, err)
+	if err != nil {
+		s.releaseLeases(ctx, ids)
+	}
+	s.refreshMetrics(ctx)
+	return len(ids), err
+}
+
+// releaseLeases returns a failed batch to the queue right away instead of
+// after the lease expires.
+func (s *ClusterService) releaseLeases(ctx context.Context, ids []int64) {
+	if err := s.docRepo.ReleaseLeases(context.WithoutCancel(ctx), ids); err != nil {
+		s.log.Warn(ctx, "cluster worker: release leases failed", logger.FieldAny("error", err))
+	}
+}
+
+// CleanupEmptyClusters deletes clusters of the active generation that no
+// document belongs to, such as k-means clusters that ended up empty after a
+// full recluster.
+func (s *ClusterService) CleanupEmptyClusters(ctx context.Context) (int64, error) {
+	if s.clusterRepo == nil {
+		return 0, fmt.Errorf("cluster service: cluster repo is nil")
+	}
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	unlock, err := s.lockWrites(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: lock cleanup: %w", err)
+	}
+	defer unlock()
+
+	deleted, err := s.clusterRepo.DeleteEmpty(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cluster service: %w", err)
+	}
+	if deleted > 0 {
+		s.log.Info(ctx, "cluster worker: deleted empty clusters", logger.FieldAny("clusters", deleted))
+	}
+	return deleted, nil
+}
+
+// lockBatch decides how the batch is clustered and takes the matching lock:
+// batches are written under a shared lock, except the first fit, which must
+// be exclusive so that two instances do not each create an initial set of
+// clusters.
+func (s *ClusterService) lockBatch(ctx context.Context) (string, func(), error) {
+	if !s.cfg.Incremental {
+		unlock, err := s.lockWrites(ctx, false)
+		return cluster.RunKindBatch, unlock, err
+	}
+	exclusive := false
+	for {
+		unlock, err := s.lockWrites(ctx, exclusive)
+		if err != nil {
+			return "", nil, err
+		}
+		existing, err := s.clusterRepo.Count(ctx, string(s.metric))
+		if err != nil {
+			unlock()
+			return "", nil, fmt.Errorf("count clusters: %w", err)
+		}
+		if existing > 0 {
+			return cluster.RunKindIncremental, unlock, nil
+		}
+		if exclusive {
+			return cluster.RunKindBatch, unlock, nil
+		}
+		unlock()
+		exclusive = true
+	}
+}
+
+// withinTx runs fn as one unit of work when a transactor is configured.
+func (s *ClusterService) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+	return s.tx.WithinTx(ctx, fn)
+}
+
+func (s *ClusterService) batchSize() int {
+	if s.cfg.BatchSize <= 0 {
+		return 1000
+	}
+	return s.cfg.BatchSize
+}
+
+func (s *ClusterService) leaseTTL() time.Duration {
+	if s.cfg.LeaseTTL <= 0 {
+		return 5 * time.Minute
+	}
+	return s.cfg.LeaseTTL
+}
+
+func (s *ClusterService) fitBatch(ctx context.Context, runID, seed int64, ids []int64, points [][]float32) (runSummary, error) {
+	s.log.Info(ctx, "cluster worker: processing batch", logger.FieldAny("run_id", runID), logger.FieldAny("size", len(points)))
+
+	opts := s.options(seed, len(points))
+	_, res, quality, err := s.fit(ctx, opts, points)
+	if err != nil {
+		return runSummary{}, err
+	}
+	summary := runSummary{K: res.leafCount(), Diagnostics: res.Diagnostics}
+	assignments := res.Assignments
+
+	// Clusters and assignments are written as one unit, so a failure leaves
+	// neither orphan clusters nor half-assigned batches behind.
+	var clusterIDs []int64
+	var noise []int64
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		var err error
+		clusterIDs, err = s.createClusters(ctx, runID, nil, res, quality)
+		if err != nil {
+			return err
+		}
+		if err := s.saveProjection(ctx, runID, nil, res); err != nil {
+			return err
+		}
+
+		buckets := make(map[int64][]int64, len(clusterIDs))
+		noise = nil
+		for i, clusterIdx := range assignments {
+			if clusterIdx == NoiseLabel {
+				noise = append(noise, ids[i])
+				continue
+			}
+			clusterID := clusterIDs[clusterIdx]
+			buckets[clusterID] = append(buckets[clusterID], ids[i])
+		}
+
+		for clusterID, docIDs := range buckets {
+			if err := s.assign(ctx, runID, docIDs, clusterID); err != nil {
+				return fmt.Errorf("update cluster ids: %w", err)
+			}
+		}
+		if err := s.markNoise(ctx, runID, noise); err != nil {
+			return fmt.Errorf("mark noise: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Error(ctx, "cluster worker: batch rolled back", logger.FieldAny("run_id", runID), logger.FieldAny("error", err))
+		return summary, err
 	}
+	summary.Docs = len(assignments)
+	s.log.Info(ctx, "cluster worker: updated docs",
+		logger.FieldAny("docs", len(points)),
+		logger.FieldAny("clusters", len(clusterIDs)),
+		logger.FieldAny("noise", len(noise)),
+	)
+	s.recordQuality(ctx, runID, s.cfg.Algorithm, summary.K, len(points), quality, clusterIDs)
+	return summary, nil
+}
 
+func (s *ClusterService) options(seed int64, n int) Options {
 	k := s.cfg.K
 	if k <= 0 {
 		k = 10
 	}
-	if k > len(points) {
-		k = len(points)
+	if k > n {
+		k = n
+	}
+	return Options{
+		Config: s.cfg,
+		Metric: s.metric,
+		K:      k,
+		Rand:   newRand(seed),
+		Sample: s.corpusSampler(),
+		RegionQuery: func(ctx context.Context, point []float32, eps float64, limit int) (int, error) {
+			return s.docRepo.CountNeighbors(ctx, point, eps, limit, string(s.metric))
+		},
+	}
+}
+
+// fit reduces the points with PCA and selects K when configured, fits the
+// clusterer and scores the result. The returned Clusterer can Predict further
+// points with the fitted model; centroids are always in the original space.
+func (s *ClusterService) fit(ctx context.Context, opts Options, points [][]float32) (Clusterer, Result, Quality, error) {
+	proj, err := s.fitProjection(ctx, points)
+	if err != nil {
+		return nil, Result{}, Quality{}, err
+	}
+	fitPoints := points
+	if proj != nil {
+		fitPoints = s.metric.prepare(proj.applyAll(points))
+		if sample := opts.Sample; sample != nil {
+			opts.Sample = func(ctx context.Context, n int) ([][]float32, error) {
+				sampled, err := sample(ctx, n)
+				if err != nil {
+					return nil, err
+				}
+				return proj.applyAll(s.metric.prepare(sampled)), nil
+			}
+		}
+	}
+
+	var kScores []KScore
+	if s.cfg.AutoK && !s.labelsNoise {
+		opts.K, kScores, err = selectK(ctx, s.factory, opts, fitPoints)
+		if err != nil {
+			return nil, Result{}, Quality{}, fmt.Errorf("auto k selection: %w", err)
+		}
+		s.log.Info(ctx, "cluster worker: auto k selected", logger.FieldAny("k", opts.K), logger.FieldAny("scores", kScores))
+	}
+
+	model := s.factory(opts)
+	res, err := model.Fit(ctx, fitPoints)
+	if err != nil {
+		return nil, Result{}, Quality{}, fmt.Errorf("fit %s: %w", s.cfg.Algorithm, err)
+	}
+	if proj != nil {
+		res.Centroids = liftCentroids(points, res, proj, s.metric)
+		res.Diagnostics.PCADims = proj.dims()
+		res.Diagnostics.ExplainedVariance = proj.explained
+		res.projection = proj
+		model = projectedModel{Clusterer: model, proj: proj, metric: s.metric}
+	}
+	s.log.Info(ctx, "cluster worker: fit finished",
+		logger.FieldAny("algorithm", s.cfg.Algorithm),
+		logger.FieldAny("k", opts.K),
+		logger.FieldAny("iterations", res.Diagnostics.Iterations),
+		logger.FieldAny("converged", res.Diagnostics.Converged),
+		logger.FieldAny("inertia", res.Diagnostics.Inertia),
+	)
+	res.Diagnostics.KScores = kScores
+	quality := evaluate(points, res.Assignments, res.Centroids, s.metric, s.cfg.QualitySample, opts)
+	return model, res, quality, nil
+}
+
+// fitProjection fits the configured PCA stage over points, or returns nil
+// when it is disabled. Density-based clusterers count eps-neighbourhoods on
+// the stored embeddings and always work in full dimension.
+func (s *ClusterService) fitProjection(ctx context.Context, points [][]float32) (*projection, error) {
+	if (s.cfg.PCADims <= 0 && s.cfg.PCAVariance <= 0) || s.labelsNoise || len(points) < 2 {
+		return nil, nil
+	}
+	if s.cfg.PCADims >= len(points[0]) {
+		return nil, nil
+	}
+	proj, err := fitPCA(points, s.cfg.PCADims, s.cfg.PCAVariance)
+	if err != nil {
+		return nil, fmt.Errorf("fit projection: %w", err)
 	}
+	s.log.Info(ctx, "cluster worker: projection fitted",
+		logger.FieldAny("dims", proj.dims()),
+		logger.FieldAny("explained_variance", proj.explained),
+	)
+	return proj, nil
+}
 
-	s.log.Info(ctx, "cluster worker: processing batch", logger.FieldAny("size", len(points)), logger.FieldAny("k", k))
-	assignments, centroids := assignOnePass(points, k)
+// saveProjection persists the projection res was fitted with, if any, so
+// that incremental assignment projects new documents the same way. A nil
+// generationID attaches it to the active generation.
+func (s *ClusterService) saveProjection(ctx context.Context, runID int64, generationID *int64, res Result) error {
+	if res.projection == nil {
+		return nil
+	}
+	p := res.projection.toModel()
+	p.RunID = runID
+	p.GenerationID = generationID
+	if _, err := s.clusterRepo.SaveProjection(ctx, p); err != nil {
+		return fmt.Errorf("save projection: %w", err)
+	}
+	return nil
+}
 
-	clusterIDs := make([]int64, len(centroids))
-	for i, centroid := range centroids {
-		id, err := s.clusterRepo.Create(ctx, cluster.Cluster{
-			Algorithm: "simple",
-			K:         k,
-			Centroid:  centroid,
-		})
+// createClusters stores the fitted centroids, parents before children, and
+// returns their ids indexed like res.Centroids. A nil generationID puts them
+// into the active generation.
+func (s *ClusterService) createClusters(ctx context.Context, runID int64, generationID *int64, res Result, quality Quality) ([]int64, error) {
+	clusterIDs := make([]int64, len(res.Centroids))
+	levels := make([]int, len(res.Centroids))
+	for i, centroid := range res.Centroids {
+		c := cluster.Cluster{
+			Algorithm:    s.cfg.Algorithm,
+			Metric:       string(s.metric),
+			K:            res.leafCount(),
+			RunID:        &runID,
+			GenerationID: generationID,
+			Centroid:     centroid,
+			Cohesion:     quality.Cohesion[i],
+		}
+		if res.Parents != nil && res.Parents[i] >= 0 {
+			parentID := clusterIDs[res.Parents[i]]
+			levels[i] = levels[res.Parents[i]] + 1
+			c.ParentID = &parentID
+			c.Level = levels[i]
+		}
+		id, err := s.clusterRepo.Create(ctx, c)
 		if err != nil {
-			s.log.Error(ctx, "cluster worker: create cluster failed", logger.FieldAny("error", err))
-			return
+			return nil, fmt.Errorf("create cluster: %w", err)
 		}
 		clusterIDs[i] = id
 	}
+	return clusterIDs, nil
+}
 
-	buckets := make(map[int64][]int64, len(clusterIDs))
-	for i, clusterIdx := range assignments {
-		clusterID := clusterIDs[clusterIdx]
-		buckets[clusterID] = append(buckets[clusterID], ids[i])
+func (s *ClusterService) assign(ctx context.Context, runID int64, docIDs []int64, clusterID int64) error {
+	if err := s.docRepo.UpdateClusterIDs(ctx, docIDs, clusterID); err != nil {
+		return err
 	}
+	return s.clusterRepo.SaveAssignments(ctx, runID, docIDs, &clusterID)
+}
 
-	for clusterID, docIDs := range buckets {
-		if err := s.docRepo.UpdateClusterIDs(ctx, docIDs, clusterID); err != nil {
-			s.log.Error(ctx, "cluster worker: update cluster ids failed", logger.FieldAny("error", err))
-			continue
+func (s *ClusterService) markNoise(ctx context.Context, runID int64, docIDs []int64) error {
+	if err := s.docRepo.MarkNoise(ctx, docIDs); err != nil {
+		return err
+	}
+	return s.clusterRepo.SaveAssignments(ctx, runID, docIDs, nil)
+}
+
+func (s *ClusterService) recordQuality(ctx context.Context, runID int64, algorithm string, k, docs int, q Quality, clusterIDs []int64) {
+	metrics.SetClusterQuality(q.Inertia, q.Silhouette, q.DaviesBouldin)
+	if len(clusterIDs) > 0 {
+		metrics.ResetClusterCohesion()
+	}
+	for i, id := range clusterIDs {
+		if q.Cohesion[i] != nil {
+			metrics.SetClusterCohesion(id, *q.Cohesion[i])
 		}
 	}
-	s.log.Info(ctx, "cluster worker: updated docs", logger.FieldAny("docs", len(points)), logger.FieldAny("clusters", len(clusterIDs)))
 
+	if _, err := s.clusterRepo.SaveQuality(ctx, cluster.Quality{
+		RunID:         runID,
+		Algorithm:     algorithm,
+		Metric:        string(s.metric),
+		K:             k,
+		Docs:          docs,
+		Inertia:       q.Inertia,
+		Silhouette:    q.Silhouette,
+		DaviesBouldin: q.DaviesBouldin,
+	}); err != nil {
+		s.log.Error(ctx, "cluster worker: save quality failed", logger.FieldAny("error", err))
+	}
+	s.log.Info(ctx, "cluster worker: quality",
+		logger.FieldAny("inertia", q.Inertia),
+		logger.FieldAny("silhouette", q.Silhouette),
+		logger.FieldAny("davies_bouldin", q.DaviesBouldin),
+	)
+}
+
+func (s *ClusterService) refreshMetrics(ctx context.Context) {
 	minSize, maxSize, avgSize, err := s.clusterRepo.SizeStats(ctx)
 	if err != nil {
 		s.log.Error(ctx, "cluster worker: size stats failed", logger.FieldAny("error", err))
@@ -113,4 +506,32 @@ func (s *ClusterService) processBatch(ctx context.Context) {
 	} else {
 		metrics.SetPctClustered(pct)
 	}
+
+	live, err := s.clusterRepo.LiveIDs(ctx)
+	if err != nil {
+		s.log.Error(ctx, "cluster worker: list live clusters failed", logger.FieldAny("error", err))
+	} else {
+		metrics.RetainClusters(live)
+	}
+}
+
+func (s *ClusterService) corpusSampler() sampleFunc {
+	var total int64 = -1
+	return func(ctx context.Context, n int) ([][]float32, error) {
+		if total < 0 {
+			count, err := s.docRepo.Count(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cluster service: count documents: %w", err)
+			}
+			total = count
+		}
+		if total == 0 {
+			return nil, nil
+		}
+		fraction := 1.5 * float64(n) / float64(total)
+		if fraction > 1 {
+			fraction = 1
+		}
+		return s.docRepo.SampleEmbeddings(ctx, fraction, n)
+	}
 }
@@ -3,6 +3,7 @@ package cluster
 import (
 	"context"
 	"testing"
+	"time"
 
 	"NeoBIT/internal/config"
 	"NeoBIT/internal/logger"
@@ -20,13 +21,145 @@ func (f *fakeClusterRepo) List(ctx context.Context, limit, offset int) ([]cluste
 	return []cluster.Cluster{{ID: 1}}, nil
 }
 
+func (f *fakeClusterRepo) Count(ctx context.Context, metric string) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakeClusterRepo) Nearest(ctx context.Context, embedding []float32, metric string) (cluster.Cluster, float64, error) {
+	return cluster.Cluster{ID: 1}, 0, nil
+}
+
+func (f *fakeClusterRepo) UpdateCentroid(ctx context.Context, id int64, centroid []float32) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) ListChildren(ctx context.Context, parentID int64, limit, offset int) ([]cluster.Cluster, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) ListNodes(ctx context.Context) ([]cluster.Cluster, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) LiveIDs(ctx context.Context) ([]int64, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) SaveQuality(ctx context.Context, q cluster.Quality) (int64, error) {
+	return 1, nil
+}
+
 func (f *fakeClusterRepo) SizeStats(ctx context.Context) (float64, float64, float64, error) {
 	return 0, 0, 0, nil
 }
 
+func (f *fakeClusterRepo) CreateRun(ctx context.Context, run cluster.Run) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakeClusterRepo) FinishRun(ctx context.Context, run cluster.Run) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) GetRun(ctx context.Context, id int64) (cluster.Run, error) {
+	return cluster.Run{ID: id}, nil
+}
+
+func (f *fakeClusterRepo) ListRuns(ctx context.Context, limit, offset int) ([]cluster.Run, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) SaveAssignments(ctx context.Context, runID int64, docIDs []int64, clusterID *int64) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) Rollback(ctx context.Context, rollbackID, targetID int64) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) CreateGeneration(ctx context.Context, runID int64) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakeClusterRepo) ActivateGeneration(ctx context.Context, generationID, runID int64) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) DiscardGeneration(ctx context.Context, generationID int64) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) PruneGenerations(ctx context.Context, keep int) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) PruneDriftHistory(ctx context.Context, keep int) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) DeleteEmpty(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) Get(ctx context.Context, id int64) (cluster.Cluster, error) {
+	return cluster.Cluster{ID: id}, nil
+}
+
+func (f *fakeClusterRepo) CentroidStats(ctx context.Context) ([]cluster.CentroidStat, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) UpdateCentroids(ctx context.Context, updates []cluster.CentroidUpdate) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) SaveLabels(ctx context.Context, labels []cluster.Label) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) PatchLabel(ctx context.Context, id int64, patch cluster.LabelPatch) error {
+	return nil
+}
+
+func (f *fakeClusterRepo) DriftHistory(ctx context.Context, clusterID int64, limit int) ([]cluster.DriftPoint, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) MergeClusters(ctx context.Context, runID, sourceID, targetID int64) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) CentroidStat(ctx context.Context, clusterID int64) (cluster.CentroidStat, error) {
+	return cluster.CentroidStat{}, nil
+}
+
+func (f *fakeClusterRepo) ScoreOutliers(ctx context.Context, z float64) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) Leaves(ctx context.Context, metric string) ([]cluster.Cluster, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) SaveProjection(ctx context.Context, p cluster.Projection) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeClusterRepo) ActiveProjection(ctx context.Context) (*cluster.Projection, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) Overlap(ctx context.Context, by string, a, b int64) ([]cluster.OverlapCell, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterRepo) ClusteringExists(ctx context.Context, by string, id int64) (bool, error) {
+	return true, nil
+}
+
 type fakeDocRepo struct{}
 
-func (f *fakeDocRepo) ListUnclustered(ctx context.Context, limit int) ([]document.Document, error) {
+func (f *fakeDocRepo) ClaimUnclustered(ctx context.Context, limit int, lease time.Duration) ([]document.Document, error) {
 	return nil, nil
 }
 
@@ -38,15 +171,81 @@ func (f *fakeDocRepo) PctClustered(ctx context.Context) (float64, error) {
 	return 0, nil
 }
 
+func (f *fakeDocRepo) CountUnclustered(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeDocRepo) Count(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeDocRepo) SampleEmbeddings(ctx context.Context, fraction float64, limit int) ([][]float32, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) CountNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeDocRepo) ClusteredNeighbors(ctx context.Context, embedding []float32, radius float64, limit int, metric string) ([]document.Document, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) MarkNoise(ctx context.Context, ids []int64) error {
+	return nil
+}
+
+func (f *fakeDocRepo) ReleaseLeases(ctx context.Context, ids []int64) error {
+	return nil
+}
+
+func (f *fakeDocRepo) ListLabelTexts(ctx context.Context, perCluster int) ([]document.Document, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) ListTextsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) ListEmbeddingsAfter(ctx context.Context, afterID int64, limit int) ([]document.Document, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) ListClusterEmbeddings(ctx context.Context, clusterID int64) ([]document.Document, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) SaveMapCoordinates(ctx context.Context, ids []int64, xs, ys []float32) error {
+	return nil
+}
+
+func (f *fakeDocRepo) RankMapPoints(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeDocRepo) NearDuplicates(ctx context.Context, id int64, embedding []float32, maxDistance float64, limit int) ([]int64, error) {
+	return nil, nil
+}
+
+func (f *fakeDocRepo) SaveDuplicateSets(ctx context.Context, sets map[int64]int64) error {
+	return nil
+}
+
 func TestClusterServiceListNilRepo(t *testing.T) {
-	svc := NewService(nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	svc, err := NewService(nil, nil, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if _, err := svc.List(context.Background(), 10, 0); err == nil {
 		t.Fatalf("expected error with nil repo")
 	}
 }
 
 func TestClusterServiceList(t *testing.T) {
-	svc := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, config.DefaultClusterConfig(), logger.Nop())
+	svc, err := NewService(&fakeClusterRepo{}, &fakeDocRepo{}, nil, nil, config.DefaultClusterConfig(), logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	res, err := svc.List(context.Background(), 10, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
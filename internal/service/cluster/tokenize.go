@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	htmlTag = regexp.MustCompile(`<[^>]*>`)
+	webURL  = regexp.MustCompile(`https?://\S+`)
+)
+
+// tokenize lowercases HN title or comment text, drops markup, links, numbers,
+// single letters and stop words, and returns the remaining words. Two-letter
+// words are kept so that terms like "go", "ai" or "c#" can label a cluster.
+func tokenize(text string) []string {
+	text = html.UnescapeString(htmlTag.ReplaceAllString(text, " "))
+	text = webURL.ReplaceAllString(text, " ")
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	out := fields[:0]
+	for _, f := range fields {
+		f = strings.TrimLeft(f, "+#")
+		if len([]rune(f)) < 2 || stopWords[f] || isNumber(f) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var stopWords = func() map[string]bool {
+	words := strings.Fields(`
+		am an as at be by do he if in is it me my no of on or so to up us we
+		ll re ve vs eg ie ok
+		about above after again against all also although always among and another any anyone
+		anything are around because been before being below between both but can cannot could
+		did does doing done down during each either else enough even ever every few for from
+		further get gets getting got had has have having her here hers herself him himself his
+		how however into its itself just know last least less let like made make makes many may
+		maybe might more most much must myself need never new next not nothing now off often
+		once one only other others our ours ourselves out over own per please quite rather really
+		said same say see seem seems several shall she should since some someone something still
+		such than that the their theirs them themselves then there these they thing things think
+		this those though through thus too under until upon use used using very want was way
+		well were what whatever when where whether which while who whom whose why will with
+		within without would yes yet you your yours yourself yourselves
+		don doesn didn isn aren wasn weren won wouldn shouldn couldn
+		http https www com org html href rel nofollow quot amp
+		show ask tell hn
+	`)
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}()
@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	"NeoBIT/internal/models/document"
+)
+
+// snapshotTransactor emulates a rollback by restoring the clusters held by
+// repo when fn fails.
+type snapshotTransactor struct {
+	repo  *runRecordingRepo
+	calls int
+}
+
+func (t *snapshotTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.calls++
+	saved := append([]cluster.Cluster{}, t.repo.clusters...)
+	if err := fn(ctx); err != nil {
+		t.repo.clusters = saved
+		return err
+	}
+	return nil
+}
+
+type failingDocRepo struct {
+	batchDocRepo
+	released []int64
+}
+
+func (f *failingDocRepo) UpdateClusterIDs(ctx context.Context, ids []int64, clusterID int64) error {
+	return errors.New("connection reset")
+}
+
+func (f *failingDocRepo) ReleaseLeases(ctx context.Context, ids []int64) error {
+	f.released = append(f.released, ids...)
+	return nil
+}
+
+func TestProcessBatchRollsBackOnFailure(t *testing.T) {
+	clusters := &runRecordingRepo{assignments: map[int64]map[int64]*int64{}}
+	docs := &failingDocRepo{batchDocRepo: batchDocRepo{
+		docs: []document.Document{
+			{ID: 1, Embedding: []float32{0, 0}},
+			{ID: 2, Embedding: []float32{10, 0}},
+		},
+	}}
+	tx := &snapshotTransactor{repo: clusters}
+	cfg := config.DefaultClusterConfig()
+	cfg.Metric = "l2"
+	cfg.K = 2
+	svc, err := NewService(clusters, docs, tx, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := svc.processBatch(context.Background()); err == nil {
+		t.Fatal("expected batch to fail")
+	}
+	if tx.calls != 1 {
+		t.Fatalf("expected one unit of work, got %d", tx.calls)
+	}
+	if len(clusters.clusters) != 0 {
+		t.Fatalf("expected cluster inserts to be rolled back, got %d clusters", len(clusters.clusters))
+	}
+	if len(clusters.finished) != 1 || clusters.finished[0].Status != cluster.RunStatusFailed {
+		t.Fatalf("expected a failed run, got %+v", clusters.finished)
+	}
+	if len(docs.released) != 2 {
+		t.Fatalf("expected the batch's leases released, got %v", docs.released)
+	}
+}
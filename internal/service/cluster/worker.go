@@ -2,31 +2,159 @@ package cluster
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
+
+	"NeoBIT/internal/logger"
 )
 
-func StartWorker(ctx context.Context, svc *ClusterService) <-chan struct{} {
+// StartWorker clusters new documents in the background. It wakes on every
+// signal from wake (debounced) and additionally polls as a fallback: every
+// Interval without a wake channel, every PollInterval with one.
+func StartWorker(ctx context.Context, svc *ClusterService, wake <-chan struct{}) <-chan struct{} {
 	done := make(chan struct{})
+	if svc == nil || svc.clusterRepo == nil || svc.docRepo == nil {
+		close(done)
+		return done
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
 	go func() {
-		defer close(done)
-		if svc == nil || svc.clusterRepo == nil || svc.docRepo == nil {
-			return
-		}
+		defer wg.Done()
 		interval := svc.cfg.Interval
 		if interval <= 0 {
 			interval = 5 * time.Second
 		}
-		ticker := time.NewTicker(interval)
+		poll := interval
+		if wake != nil && svc.cfg.PollInterval > 0 {
+			poll = svc.cfg.PollInterval
+		}
+		ticker := time.NewTicker(poll)
 		defer ticker.Stop()
 
+		// Clears clusters orphaned by failures before batches were atomic.
+		if _, err := svc.CleanupEmptyClusters(ctx); err != nil {
+			svc.log.Warn(ctx, "cluster worker: cleanup empty clusters failed", logger.FieldAny("error", err))
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
 				return
+			case _, ok := <-wake:
+				if !ok {
+					wake = nil
+					ticker.Reset(interval)
+					continue
+				}
+				if !debounce(ctx, wake, svc.cfg.Debounce) {
+					return
+				}
 			case <-ticker.C:
-				svc.processBatch(ctx)
 			}
+			svc.drain(ctx)
 		}
 	}()
+
+	schedule(ctx, &wg, svc.cfg.ReclusterInterval, func() {
+		if _, err := svc.Recluster(ctx); err != nil && !errors.Is(err, ErrReclusterRunning) {
+			svc.log.Error(ctx, "cluster worker: full recluster failed", logger.FieldAny("error", err))
+		}
+	})
+	schedule(ctx, &wg, svc.cfg.RecomputeInterval, func() {
+		if _, err := svc.RecomputeCentroids(ctx); err != nil && ctx.Err() == nil {
+			svc.log.Error(ctx, "cluster worker: recompute centroids failed", logger.FieldAny("error", err))
+		}
+	})
+	schedule(ctx, &wg, svc.cfg.LabelInterval, func() {
+		if _, err := svc.LabelClusters(ctx); err != nil && ctx.Err() == nil {
+			svc.log.Error(ctx, "cluster worker: label clusters failed", logger.FieldAny("error", err))
+		}
+	})
+	schedule(ctx, &wg, svc.cfg.DuplicateInterval, func() {
+		if _, err := svc.DetectDuplicates(ctx); err != nil && ctx.Err() == nil {
+			svc.log.Error(ctx, "cluster worker: detect duplicates failed", logger.FieldAny("error", err))
+		}
+	})
+	schedule(ctx, &wg, svc.cfg.OutlierInterval, func() {
+		if _, err := svc.ScoreOutliers(ctx); err != nil && ctx.Err() == nil {
+			svc.log.Error(ctx, "cluster worker: score outliers failed", logger.FieldAny("error", err))
+		}
+	})
+	schedule(ctx, &wg, svc.cfg.MapInterval, func() {
+		if _, err := svc.ProjectMap(ctx); err != nil && ctx.Err() == nil {
+			svc.log.Error(ctx, "cluster worker: project map failed", logger.FieldAny("error", err))
+		}
+	})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 	return done
 }
+
+// schedule runs fn every interval until ctx is done; a non-positive interval
+// disables it.
+func schedule(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, fn func()) {
+	if interval <= 0 {
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+}
+
+// drain keeps processing batches while they come back full, so a large
+// import is worked through without waiting for the next wake-up.
+func (s *ClusterService) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := s.processBatch(ctx)
+		if err != nil || n < s.batchSize() {
+			return
+		}
+	}
+}
+
+// debounce waits until wake has been quiet for d, so that a burst of inserts
+// is clustered as one batch. A steady stream is cut off after 10*d. It
+// returns false if ctx is done.
+func debounce(ctx context.Context, wake <-chan struct{}, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	quiet := time.NewTimer(d)
+	defer quiet.Stop()
+	deadline := time.NewTimer(10 * d)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case _, ok := <-wake:
+			if !ok {
+				return true
+			}
+			quiet.Reset(d)
+		case <-quiet.C:
+			return true
+		case <-deadline.C:
+			return true
+		}
+	}
+}
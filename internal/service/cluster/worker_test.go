@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"NeoBIT/internal/config"
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/document"
+)
+
+type countingDocRepo struct {
+	fakeDocRepo
+	calls atomic.Int32
+}
+
+func (c *countingDocRepo) ClaimUnclustered(ctx context.Context, limit int, lease time.Duration) ([]document.Document, error) {
+	c.calls.Add(1)
+	return nil, nil
+}
+
+func TestDebounce(t *testing.T) {
+	wake := make(chan struct{}, 1)
+	go func() {
+		for i := 0; i < 3; i++ {
+			wake <- struct{}{}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	if !debounce(context.Background(), wake, 30*time.Millisecond) {
+		t.Fatalf("expected debounce to complete")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected to wait for a quiet period, returned after %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if debounce(ctx, wake, time.Second) {
+		t.Fatalf("expected false for a cancelled context")
+	}
+}
+
+func TestStartWorkerWakesOnNotification(t *testing.T) {
+	docs := &countingDocRepo{}
+	cfg := config.DefaultClusterConfig()
+	cfg.PollInterval = time.Hour
+	cfg.Debounce = time.Millisecond
+	svc, err := NewService(&fakeClusterRepo{}, docs, nil, nil, cfg, logger.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wake := make(chan struct{}, 1)
+	done := StartWorker(ctx, svc, wake)
+
+	wake <- struct{}{}
+	deadline := time.Now().Add(time.Second)
+	for docs.calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if docs.calls.Load() != 1 {
+		t.Fatalf("expected one batch after a notification, got %d", docs.calls.Load())
+	}
+}
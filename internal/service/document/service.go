@@ -2,12 +2,22 @@ package document
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"NeoBIT/internal/logger"
 	"NeoBIT/internal/models/document"
 )
 
+const (
+	defaultRepresentatives = 10
+	maxRepresentatives     = 100
+	defaultMapPoints       = 1000
+	maxMapPoints           = 10000
+)
+
+var ErrInvalidOrder = errors.New("invalid order")
+
 type DocumentService struct {
 	repo Repository
 	log  logger.Logger
@@ -34,9 +44,51 @@ func (s *DocumentService) GetByID(ctx context.Context, id int64) (document.Docum
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *DocumentService) ListByCluster(ctx context.Context, clusterID int64, limit, offset int) ([]document.Document, error) {
+func (s *DocumentService) ListByCluster(ctx context.Context, clusterID int64, limit, offset int, order string) ([]document.Document, error) {
 	if s.repo == nil {
 		return nil, fmt.Errorf("document service: repo is nil")
 	}
-	return s.repo.ListByCluster(ctx, clusterID, limit, offset)
+	switch order {
+	case "":
+		order = document.OrderID
+	case document.OrderID, document.OrderCentrality, document.OrderScore, document.OrderTime:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrInvalidOrder, order)
+	}
+	return s.repo.ListByCluster(ctx, clusterID, limit, offset, order)
+}
+
+// Representatives returns up to n documents closest to the centroid of the
+// cluster, nearest first.
+func (s *DocumentService) Representatives(ctx context.Context, clusterID int64, n int) ([]document.Representative, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("document service: repo is nil")
+	}
+	if n <= 0 {
+		n = defaultRepresentatives
+	}
+	n = min(n, maxRepresentatives)
+	return s.repo.Representatives(ctx, clusterID, n)
+}
+
+// Duplicates returns the documents found to be near duplicates of document
+// id, lowest id first.
+func (s *DocumentService) Duplicates(ctx context.Context, id int64) ([]document.Document, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("document service: repo is nil")
+	}
+	return s.repo.Duplicates(ctx, id)
+}
+
+// Map returns up to limit documents placed on the cluster map: those of one
+// cluster when clusterID is set, otherwise a sample spread across clusters.
+func (s *DocumentService) Map(ctx context.Context, clusterID *int64, limit int) ([]document.MapPoint, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("document service: repo is nil")
+	}
+	if limit <= 0 {
+		limit = defaultMapPoints
+	}
+	limit = min(limit, maxMapPoints)
+	return s.repo.MapPoints(ctx, clusterID, limit)
 }
@@ -12,6 +12,8 @@ import (
 type fakeRepo struct {
 	createID  int64
 	createErr error
+	order     string
+	n         int
 }
 
 func (f *fakeRepo) Create(ctx context.Context, doc document.Document) (int64, error) {
@@ -22,8 +24,23 @@ func (f *fakeRepo) GetByID(ctx context.Context, id int64) (document.Document, er
 	return document.Document{}, errors.New("not implemented")
 }
 
-func (f *fakeRepo) ListByCluster(ctx context.Context, clusterID int64, limit, offset int) ([]document.Document, error) {
-	return nil, errors.New("not implemented")
+func (f *fakeRepo) ListByCluster(ctx context.Context, clusterID int64, limit, offset int, order string) ([]document.Document, error) {
+	f.order = order
+	return nil, nil
+}
+
+func (f *fakeRepo) Representatives(ctx context.Context, clusterID int64, n int) ([]document.Representative, error) {
+	f.n = n
+	return nil, nil
+}
+
+func (f *fakeRepo) Duplicates(ctx context.Context, id int64) ([]document.Document, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) MapPoints(ctx context.Context, clusterID *int64, limit int) ([]document.MapPoint, error) {
+	f.n = limit
+	return nil, nil
 }
 
 func TestDocumentServiceCreate(t *testing.T) {
@@ -42,3 +59,42 @@ func TestDocumentServiceCreate(t *testing.T) {
 		t.Fatalf("expected id=42, got %d", id)
 	}
 }
+
+func TestDocumentServiceListByClusterOrder(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo, logger.Nop())
+
+	if _, err := svc.ListByCluster(context.Background(), 1, 20, 0, ""); err != nil || repo.order != document.OrderID {
+		t.Fatalf("expected default id order, got %q %v", repo.order, err)
+	}
+	if _, err := svc.ListByCluster(context.Background(), 1, 20, 0, document.OrderCentrality); err != nil || repo.order != document.OrderCentrality {
+		t.Fatalf("expected centrality order, got %q %v", repo.order, err)
+	}
+	if _, err := svc.ListByCluster(context.Background(), 1, 20, 0, "random"); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestDocumentServiceRepresentativesClampsN(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo, logger.Nop())
+
+	if _, err := svc.Representatives(context.Background(), 1, 0); err != nil || repo.n != defaultRepresentatives {
+		t.Fatalf("expected default n, got %d %v", repo.n, err)
+	}
+	if _, err := svc.Representatives(context.Background(), 1, 1000); err != nil || repo.n != maxRepresentatives {
+		t.Fatalf("expected n capped at %d, got %d %v", maxRepresentatives, repo.n, err)
+	}
+}
+
+func TestDocumentServiceMapClampsLimit(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo, logger.Nop())
+
+	if _, err := svc.Map(context.Background(), nil, 0); err != nil || repo.n != defaultMapPoints {
+		t.Fatalf("expected default limit, got %d %v", repo.n, err)
+	}
+	if _, err := svc.Map(context.Background(), nil, 1_000_000); err != nil || repo.n != maxMapPoints {
+		t.Fatalf("expected limit capped at %d, got %d %v", maxMapPoints, repo.n, err)
+	}
+}
@@ -10,3 +10,8 @@ type DocumentRepository interface {
 	CreateBatch(ctx context.Context, docs []document.Document) (int64, error)
 	Count(ctx context.Context) (int64, error)
 }
+
+// Locker elects the importing instance; see db.Locker.
+type Locker interface {
+	TryLock(ctx context.Context, key int64) (func(), bool, error)
+}
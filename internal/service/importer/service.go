@@ -24,16 +24,20 @@ import (
 
 type ImportService struct {
 	docRepo DocumentRepository
+	locker  Locker
 	cfg     config.ImportConfig
 	log     logger.Logger
 }
 
-func NewService(docRepo DocumentRepository, cfg config.ImportConfig, log logger.Logger) *ImportService {
+// NewService builds the importer. locker may be nil when only one instance
+// runs; otherwise only the instance holding the import lock imports.
+func NewService(docRepo DocumentRepository, locker Locker, cfg config.ImportConfig, log logger.Logger) *ImportService {
 	if log == nil {
 		log = logger.Nop()
 	}
 	return &ImportService{
 		docRepo: docRepo,
+		locker:  locker,
 		cfg:     cfg,
 		log:     log,
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"NeoBIT/internal/db"
 	"NeoBIT/internal/logger"
 )
 
@@ -15,6 +16,14 @@ func StartWorker(ctx context.Context, svc *ImportService) <-chan struct{} {
 		if svc == nil {
 			return
 		}
+		release, err := svc.elect(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				svc.log.Error(ctx, "import worker: leader election failed", logger.FieldAny("error", err))
+			}
+			return
+		}
+		defer release()
 
 		runCtx, runCancel := context.WithCancel(context.WithoutCancel(ctx))
 		defer runCancel()
@@ -61,3 +70,36 @@ func StartWorker(ctx context.Context, svc *ImportService) <-chan struct{} {
 	}()
 	return done
 }
+
+// electRetry is how often a follower checks whether the import lock is free.
+// Polling with TryLock keeps waiting followers from pinning a pool
+// connection, which a blocking pg_advisory_lock would for the whole wait.
+const electRetry = 10 * time.Second
+
+// elect blocks until this instance holds the import lock. Followers wait
+// rather than give up, so that the import resumes elsewhere if the leader
+// dies; once they get the lock, SkipIfDocumentsExist normally ends the run.
+func (s *ImportService) elect(ctx context.Context) (func(), error) {
+	if s.locker == nil || !s.cfg.Enabled {
+		return func() {}, nil
+	}
+	ticker := time.NewTicker(electRetry)
+	defer ticker.Stop()
+	for waiting := false; ; waiting = true {
+		release, ok, err := s.locker.TryLock(ctx, db.LockImport)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return release, nil
+		}
+		if !waiting {
+			s.log.Info(ctx, "import worker: another instance is importing, waiting for the import lock")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
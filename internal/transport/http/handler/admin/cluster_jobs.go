@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/cluster"
+	clusterservice "NeoBIT/internal/service/cluster"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) StartClusterJob(w http.ResponseWriter, r *http.Request) {
+	var req cluster.CreateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Warn(r.Context(), "cluster job start: invalid json", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	job, err := h.jobs.Start(cluster.JobRequest{
+		Scope:     req.Scope,
+		Algorithm: req.Algorithm,
+		K:         req.K,
+		AutoK:     req.AutoK,
+	})
+	switch {
+	case errors.Is(err, clusterservice.ErrInvalidJob):
+		h.log.Warn(r.Context(), "cluster job start: invalid request", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, clusterservice.ErrJobRunning), errors.Is(err, clusterservice.ErrReclusterRunning):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		h.log.Error(r.Context(), "cluster job start failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to start cluster job")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, cluster.JobResponse(job))
+}
+
+func (h *Handler) GetClusterJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseJobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := h.jobs.Get(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "cluster job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster.JobResponse(job))
+}
+
+func (h *Handler) CancelClusterJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseJobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := h.jobs.Cancel(id)
+	switch {
+	case errors.Is(err, clusterservice.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, "cluster job not found")
+		return
+	case errors.Is(err, clusterservice.ErrJobNotRunning):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		h.log.Error(r.Context(), "cluster job cancel failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to cancel cluster job")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, cluster.JobResponse(job))
+}
+
+func (h *Handler) parseJobID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster job: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster job id")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,15 @@
+package admin
+
+import "NeoBIT/internal/logger"
+
+type Handler struct {
+	jobs JobService
+	log  logger.Logger
+}
+
+func NewHandler(jobs JobService, log logger.Logger) *Handler {
+	if log == nil {
+		log = logger.Nop()
+	}
+	return &Handler{jobs: jobs, log: log}
+}
@@ -0,0 +1,9 @@
+package admin
+
+import "NeoBIT/internal/models/cluster"
+
+type JobService interface {
+	Start(req cluster.JobRequest) (cluster.Job, error)
+	Get(id int64) (cluster.Job, error)
+	Cancel(id int64) (cluster.Job, error)
+}
@@ -0,0 +1,26 @@
+package cluster
+
+import (
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) Children(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster children: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	limit, offset := parseLimitOffset(r)
+	res, err := h.svc.Children(r.Context(), id, limit, offset)
+	if err != nil {
+		h.log.Error(r.Context(), "cluster children failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to list child clusters")
+		return
+	}
+	writeJSON(w, http.StatusOK, toClusterResponses(res))
+}
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 )
@@ -35,3 +36,15 @@ func parseLimitOffset(r *http.Request) (int, int) {
 	}
 	return limit, offset
 }
+
+func parseDepth(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("depth")
+	if v == "" {
+		return 3, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("depth must be a positive integer")
+	}
+	return n, nil
+}
@@ -13,3 +13,15 @@ func TestParseLimitOffset(t *testing.T) {
 		t.Fatalf("expected limit=7 offset=3, got %d %d", limit, offset)
 	}
 }
+
+func TestParseDepth(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/clusters/tree", nil)
+	if depth, err := parseDepth(req); err != nil || depth != 3 {
+		t.Fatalf("expected default depth 3, got %d %v", depth, err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/clusters/tree?depth=0", nil)
+	if _, err := parseDepth(req); err == nil {
+		t.Fatalf("expected error for non-positive depth")
+	}
+}
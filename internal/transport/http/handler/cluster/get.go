@@ -0,0 +1,37 @@
+package cluster
+
+import (
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	cluster_model "NeoBIT/internal/models/cluster"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster get: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	res, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster get: not found", logger.FieldAny("error", err))
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, toClusterDetailResponse(res))
+}
+
+func toClusterDetailResponse(detail cluster_model.ClusterDetail) cluster_model.ClusterDetailResponse {
+	history := make([]cluster_model.DriftPointResponse, 0, len(detail.DriftHistory))
+	for _, p := range detail.DriftHistory {
+		history = append(history, cluster_model.DriftPointResponse(p))
+	}
+	return cluster_model.ClusterDetailResponse{
+		ClusterResponse: cluster_model.ClusterResponse(detail.Cluster),
+		DriftHistory:    history,
+	}
+}
@@ -8,4 +8,14 @@ import (
 
 type Service interface {
 	List(ctx context.Context, limit, offset int) ([]cluster.Cluster, error)
+	Get(ctx context.Context, id int64) (cluster.ClusterDetail, error)
+	PatchLabel(ctx context.Context, id int64, patch cluster.LabelPatch) (cluster.Cluster, error)
+	Merge(ctx context.Context, sourceID, targetID int64) (cluster.Run, error)
+	Split(ctx context.Context, id int64, k int) (cluster.Run, error)
+	Children(ctx context.Context, id int64, limit, offset int) ([]cluster.Cluster, error)
+	Tree(ctx context.Context, depth int) ([]cluster.TreeNode, error)
+	ListRuns(ctx context.Context, limit, offset int) ([]cluster.Run, error)
+	GetRun(ctx context.Context, id int64) (cluster.Run, error)
+	Rollback(ctx context.Context, id int64) (cluster.Run, error)
+	Compare(ctx context.Context, by string, a, b int64) (cluster.Comparison, error)
 }
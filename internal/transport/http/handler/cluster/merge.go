@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	cluster_model "NeoBIT/internal/models/cluster"
+	clusterservice "NeoBIT/internal/service/cluster"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster merge: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	var req cluster_model.MergeClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn(r.Context(), "cluster merge: invalid json", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.TargetID <= 0 {
+		writeError(w, http.StatusBadRequest, "target_id is required")
+		return
+	}
+	for _, clusterID := range []int64{id, req.TargetID} {
+		if _, err := h.svc.Get(r.Context(), clusterID); err != nil {
+			h.log.Warn(r.Context(), "cluster merge: not found", logger.FieldAny("error", err))
+			writeError(w, http.StatusNotFound, "cluster not found")
+			return
+		}
+	}
+
+	res, err := h.svc.Merge(r.Context(), id, req.TargetID)
+	if h.writeEditError(w, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error(r.Context(), "cluster merge failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to merge clusters")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster_model.RunResponse(res))
+}
+
+func (h *Handler) Split(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster split: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	k := 0
+	if v := r.URL.Query().Get("k"); v != "" {
+		if k, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, "k must be an integer")
+			return
+		}
+	}
+	if _, err := h.svc.Get(r.Context(), id); err != nil {
+		h.log.Warn(r.Context(), "cluster split: not found", logger.FieldAny("error", err))
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+
+	res, err := h.svc.Split(r.Context(), id, k)
+	if h.writeEditError(w, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error(r.Context(), "cluster split failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to split cluster")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster_model.RunResponse(res))
+}
+
+// writeEditError answers the validation errors of Merge and Split and
+// reports whether it did.
+func (h *Handler) writeEditError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, clusterservice.ErrInvalidMerge), errors.Is(err, clusterservice.ErrInvalidSplit):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, clusterservice.ErrClusterNotEditable):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	cluster_model "NeoBIT/internal/models/cluster"
+	clusterservice "NeoBIT/internal/service/cluster"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster patch: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	var req cluster_model.PatchClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn(r.Context(), "cluster patch: invalid json", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if _, err := h.svc.Get(r.Context(), id); err != nil {
+		h.log.Warn(r.Context(), "cluster patch: not found", logger.FieldAny("error", err))
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+
+	res, err := h.svc.PatchLabel(r.Context(), id, cluster_model.LabelPatch{Label: req.Label, Keywords: req.Keywords})
+	if errors.Is(err, clusterservice.ErrInvalidLabel) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Error(r.Context(), "cluster patch failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to update cluster")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster_model.ClusterResponse(res))
+}
@@ -0,0 +1,107 @@
+package cluster
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	cluster_model "NeoBIT/internal/models/cluster"
+	clusterservice "NeoBIT/internal/service/cluster"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parseLimitOffset(r)
+	res, err := h.svc.ListRuns(r.Context(), limit, offset)
+	if err != nil {
+		h.log.Error(r.Context(), "cluster runs list failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to list cluster runs")
+		return
+	}
+	out := make([]cluster_model.RunResponse, 0, len(res))
+	for _, run := range res {
+		out = append(out, cluster_model.RunResponse(run))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *Handler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster run get: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster run id")
+		return
+	}
+	res, err := h.svc.GetRun(r.Context(), id)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster run get: not found", logger.FieldAny("error", err))
+		writeError(w, http.StatusNotFound, "cluster run not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster_model.RunResponse(res))
+}
+
+func (h *Handler) Rollback(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster run rollback: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster run id")
+		return
+	}
+	if _, err := h.svc.GetRun(r.Context(), id); err != nil {
+		h.log.Warn(r.Context(), "cluster run rollback: not found", logger.FieldAny("error", err))
+		writeError(w, http.StatusNotFound, "cluster run not found")
+		return
+	}
+	res, err := h.svc.Rollback(r.Context(), id)
+	if errors.Is(err, clusterservice.ErrRunNotSucceeded) {
+		writeError(w, http.StatusConflict, "only succeeded runs can be rolled back to")
+		return
+	}
+	if errors.Is(err, clusterservice.ErrRunPruned) {
+		writeError(w, http.StatusConflict, "the run's cluster generation has been pruned")
+		return
+	}
+	if err != nil {
+		h.log.Error(r.Context(), "cluster run rollback failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to roll back cluster run")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster_model.RunResponse(res))
+}
+
+func (h *Handler) Compare(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	a, errA := strconv.ParseInt(q.Get("a"), 10, 64)
+	b, errB := strconv.ParseInt(q.Get("b"), 10, 64)
+	if errA != nil || errB != nil {
+		writeError(w, http.StatusBadRequest, "a and b must be cluster run or generation ids")
+		return
+	}
+	by := q.Get("by")
+	if by == "" || by == cluster_model.CompareByRun {
+		for _, id := range []int64{a, b} {
+			if _, err := h.svc.GetRun(r.Context(), id); err != nil {
+				h.log.Warn(r.Context(), "cluster run compare: not found", logger.FieldAny("error", err))
+				writeError(w, http.StatusNotFound, "cluster run not found")
+				return
+			}
+		}
+	}
+	res, err := h.svc.Compare(r.Context(), by, a, b)
+	if errors.Is(err, clusterservice.ErrInvalidComparison) {
+		writeError(w, http.StatusBadRequest, "by must be run or generation")
+		return
+	}
+	if errors.Is(err, clusterservice.ErrNoCommonDocuments) || errors.Is(err, clusterservice.ErrClusteringGone) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Error(r.Context(), "cluster run compare failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to compare clusterings")
+		return
+	}
+	writeJSON(w, http.StatusOK, cluster_model.ComparisonResponse(res))
+}
@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"net/http"
+
+	"NeoBIT/internal/logger"
+	cluster_model "NeoBIT/internal/models/cluster"
+)
+
+func (h *Handler) Tree(w http.ResponseWriter, r *http.Request) {
+	depth, err := parseDepth(r)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster tree: invalid depth", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid depth")
+		return
+	}
+	res, err := h.svc.Tree(r.Context(), depth)
+	if err != nil {
+		h.log.Error(r.Context(), "cluster tree failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to build cluster tree")
+		return
+	}
+	writeJSON(w, http.StatusOK, toClusterTreeResponses(res))
+}
+
+func toClusterTreeResponses(nodes []cluster_model.TreeNode) []cluster_model.ClusterTreeResponse {
+	out := make([]cluster_model.ClusterTreeResponse, 0, len(nodes))
+	for _, node := range nodes {
+		out = append(out, cluster_model.ClusterTreeResponse{
+			ID:        node.ID,
+			Algorithm: node.Algorithm,
+			Metric:    node.Metric,
+			K:         node.K,
+			ParentID:  node.ParentID,
+			Level:     node.Level,
+			Label:     node.Label,
+			Size:      node.Size,
+			Children:  toClusterTreeResponses(node.Children),
+		})
+	}
+	return out
+}
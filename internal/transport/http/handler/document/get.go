@@ -26,6 +26,27 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, toDocumentResponse(res))
 }
 
+func (h *Handler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "document duplicates: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid document id")
+		return
+	}
+	if _, err := h.svc.GetByID(r.Context(), id); err != nil {
+		h.log.Warn(r.Context(), "document duplicates: not found", logger.FieldAny("error", err))
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+	res, err := h.svc.Duplicates(r.Context(), id)
+	if err != nil {
+		h.log.Error(r.Context(), "document duplicates failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to list duplicates")
+		return
+	}
+	writeJSON(w, http.StatusOK, toDocumentResponses(res))
+}
+
 func toDocumentResponse(doc document.Document) document.DocumentResponse {
 	return document.DocumentResponse(doc)
 }
@@ -9,5 +9,8 @@ import (
 type Service interface {
 	Create(ctx context.Context, doc document.Document) (int64, error)
 	GetByID(ctx context.Context, id int64) (document.Document, error)
-	ListByCluster(ctx context.Context, clusterID int64, limit, offset int) ([]document.Document, error)
+	ListByCluster(ctx context.Context, clusterID int64, limit, offset int, order string) ([]document.Document, error)
+	Representatives(ctx context.Context, clusterID int64, n int) ([]document.Representative, error)
+	Duplicates(ctx context.Context, id int64) ([]document.Document, error)
+	Map(ctx context.Context, clusterID *int64, limit int) ([]document.MapPoint, error)
 }
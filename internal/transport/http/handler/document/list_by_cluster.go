@@ -1,11 +1,13 @@
 package document
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"NeoBIT/internal/logger"
 	"NeoBIT/internal/models/document"
+	documentservice "NeoBIT/internal/service/document"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -18,7 +20,11 @@ func (h *Handler) ListByCluster(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	limit, offset := parseLimitOffset(r)
-	res, err := h.svc.ListByCluster(r.Context(), clusterID, limit, offset)
+	res, err := h.svc.ListByCluster(r.Context(), clusterID, limit, offset, r.URL.Query().Get("order"))
+	if errors.Is(err, documentservice.ErrInvalidOrder) {
+		writeError(w, http.StatusBadRequest, "order must be one of id, centrality, score, time")
+		return
+	}
 	if err != nil {
 		h.log.Error(r.Context(), "document list by cluster failed", logger.FieldAny("error", err))
 		writeError(w, http.StatusInternalServerError, "failed to list documents")
@@ -27,6 +33,36 @@ func (h *Handler) ListByCluster(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, toDocumentResponses(res))
 }
 
+func (h *Handler) Representatives(w http.ResponseWriter, r *http.Request) {
+	clusterID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.log.Warn(r.Context(), "cluster representatives: invalid id", logger.FieldAny("error", err))
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	n := 0
+	if v := r.URL.Query().Get("n"); v != "" {
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "n must be a positive integer")
+			return
+		}
+	}
+	res, err := h.svc.Representatives(r.Context(), clusterID, n)
+	if err != nil {
+		h.log.Error(r.Context(), "cluster representatives failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to list representatives")
+		return
+	}
+	out := make([]document.RepresentativeResponse, 0, len(res))
+	for _, rep := range res {
+		out = append(out, document.RepresentativeResponse{
+			DocumentResponse: toDocumentResponse(rep.Document),
+			Distance:         rep.Distance,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
 func toDocumentResponses(docs []document.Document) []document.DocumentResponse {
 	out := make([]document.DocumentResponse, 0, len(docs))
 	for _, doc := range docs {
@@ -0,0 +1,41 @@
+package document
+
+import (
+	"net/http"
+	"strconv"
+
+	"NeoBIT/internal/logger"
+	"NeoBIT/internal/models/document"
+)
+
+func (h *Handler) Map(w http.ResponseWriter, r *http.Request) {
+	var clusterID *int64
+	if v := r.URL.Query().Get("cluster_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			h.log.Warn(r.Context(), "document map: invalid cluster id", logger.FieldAny("error", err))
+			writeError(w, http.StatusBadRequest, "invalid cluster id")
+			return
+		}
+		clusterID = &id
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+	}
+	res, err := h.svc.Map(r.Context(), clusterID, limit)
+	if err != nil {
+		h.log.Error(r.Context(), "document map failed", logger.FieldAny("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to load map")
+		return
+	}
+	out := make([]document.MapPointResponse, 0, len(res))
+	for _, p := range res {
+		out = append(out, document.MapPointResponse(p))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,35 @@
+package vecmath
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minShard is the smallest range worth handing to another goroutine; below
+// it the scheduling overhead outweighs the work.
+const minShard = 1024
+
+// ParallelFor splits [0, n) into contiguous shards, at most one per CPU, and
+// calls fn for each shard concurrently. It returns when every shard is done.
+// Small ranges run on the calling goroutine.
+func ParallelFor(n int, fn func(lo, hi int)) {
+	shards := min(runtime.NumCPU(), (n+minShard-1)/minShard)
+	if shards <= 1 {
+		if n > 0 {
+			fn(0, n)
+		}
+		return
+	}
+
+	size := (n + shards - 1) / shards
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += size {
+		hi := min(lo+size, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(lo, hi)
+		}()
+	}
+	wg.Wait()
+}
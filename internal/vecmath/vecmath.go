@@ -0,0 +1,97 @@
+// Package vecmath holds the float32 kernels behind clustering. The loops are
+// unrolled by four with independent accumulators, which breaks the
+// dependency chain of a single running sum, and index fixed-size subslices so
+// that the compiler drops the bounds checks; none of them allocate.
+package vecmath
+
+import "math"
+
+// Dot returns the dot product of a and b, which must have the same length.
+func Dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		aa, bb := a[i:i+4:i+4], b[i:i+4:i+4]
+		s0 += aa[0] * bb[0]
+		s1 += aa[1] * bb[1]
+		s2 += aa[2] * bb[2]
+		s3 += aa[3] * bb[3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// SquaredL2 returns the squared Euclidean distance between a and b. Nearest
+// neighbour searches compare it directly and take the square root of the
+// winner only.
+func SquaredL2(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		aa, bb := a[i:i+4:i+4], b[i:i+4:i+4]
+		d0 := aa[0] - bb[0]
+		d1 := aa[1] - bb[1]
+		d2 := aa[2] - bb[2]
+		d3 := aa[3] - bb[3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// L2 returns the Euclidean distance between a and b.
+func L2(a, b []float32) float32 {
+	return Sqrt(SquaredL2(a, b))
+}
+
+// Norm returns the Euclidean length of v.
+func Norm(v []float32) float32 {
+	return Sqrt(Dot(v, v))
+}
+
+// Norms returns the length of every vector in vs.
+func Norms(vs [][]float32) []float32 {
+	out := make([]float32, len(vs))
+	for i, v := range vs {
+		out[i] = Norm(v)
+	}
+	return out
+}
+
+// Normalize scales v to unit length in place and returns it; a zero vector
+// is left unchanged.
+func Normalize(v []float32) []float32 {
+	n := Norm(v)
+	if n == 0 {
+		return v
+	}
+	inv := 1 / n
+	for i := range v {
+		v[i] *= inv
+	}
+	return v
+}
+
+// CosineDistance returns 1 - cos(a, b) given the precomputed lengths of a
+// and b; it is 1 when either is a zero vector.
+func CosineDistance(a, b []float32, normA, normB float32) float32 {
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - Dot(a, b)/(normA*normB)
+}
+
+// Sqrt is a float32 square root.
+func Sqrt(x float32) float32 {
+	return float32(math.Sqrt(float64(x)))
+}
@@ -0,0 +1,121 @@
+package vecmath
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+func randomVector(rnd *rand.Rand, dim int) []float32 {
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = rnd.Float32()*2 - 1
+	}
+	return v
+}
+
+func naiveDot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func TestKernelsMatchNaive(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, dim := range []int{0, 1, 3, 4, 5, 7, 8, 9, 384} {
+		a, b := randomVector(rnd, dim), randomVector(rnd, dim)
+		diff := make([]float32, dim)
+		for i := range diff {
+			diff[i] = a[i] - b[i]
+		}
+
+		if got, want := float64(Dot(a, b)), naiveDot(a, b); math.Abs(got-want) > 1e-4 {
+			t.Fatalf("dim %d: Dot = %v, want %v", dim, got, want)
+		}
+		if got, want := float64(SquaredL2(a, b)), naiveDot(diff, diff); math.Abs(got-want) > 1e-3 {
+			t.Fatalf("dim %d: SquaredL2 = %v, want %v", dim, got, want)
+		}
+		if got, want := float64(L2(a, b)), math.Sqrt(naiveDot(diff, diff)); math.Abs(got-want) > 1e-4 {
+			t.Fatalf("dim %d: L2 = %v, want %v", dim, got, want)
+		}
+	}
+}
+
+func TestNormalizeAndCosineDistance(t *testing.T) {
+	v := Normalize([]float32{3, 4})
+	if math.Abs(float64(Norm(v))-1) > 1e-6 {
+		t.Fatalf("expected unit length, got %v", Norm(v))
+	}
+	zero := Normalize([]float32{0, 0})
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Fatalf("expected zero vector to stay zero, got %v", zero)
+	}
+
+	a, b := []float32{1, 0}, []float32{0, 2}
+	if d := CosineDistance(a, b, Norm(a), Norm(b)); math.Abs(float64(d)-1) > 1e-6 {
+		t.Fatalf("expected orthogonal vectors at distance 1, got %v", d)
+	}
+	if d := CosineDistance(a, zero, Norm(a), 0); d != 1 {
+		t.Fatalf("expected distance 1 to a zero vector, got %v", d)
+	}
+}
+
+func TestParallelForCoversRangeOnce(t *testing.T) {
+	for _, n := range []int{0, 1, minShard - 1, minShard + 1, 10*minShard + 7} {
+		counts := make([]int32, n)
+		var calls atomic.Int32
+		ParallelFor(n, func(lo, hi int) {
+			calls.Add(1)
+			for i := lo; i < hi; i++ {
+				atomic.AddInt32(&counts[i], 1)
+			}
+		})
+		for i, c := range counts {
+			if c != 1 {
+				t.Fatalf("n=%d: index %d visited %d times", n, i, c)
+			}
+		}
+		if n == 0 && calls.Load() != 0 {
+			t.Fatalf("expected no calls for an empty range")
+		}
+	}
+}
+
+var sink float32
+
+func BenchmarkDot384(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	x, y := randomVector(rnd, 384), randomVector(rnd, 384)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink = Dot(x, y)
+	}
+}
+
+func BenchmarkSquaredL2_384(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	x, y := randomVector(rnd, 384), randomVector(rnd, 384)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink = SquaredL2(x, y)
+	}
+}
+
+// BenchmarkNaiveL2_384 is the rolled loop with a square root that clustering
+// used before, for comparison.
+func BenchmarkNaiveL2_384(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	x, y := randomVector(rnd, 384), randomVector(rnd, 384)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var sum float32
+		for j := range x {
+			d := x[j] - y[j]
+			sum += d * d
+		}
+		sink = float32(math.Sqrt(float64(sum)))
+	}
+}